@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/IljaN/w2d/deepl"
+	"github.com/IljaN/w2d/wikipedia"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// outputArgs are shared by the commands which write multiple articles in to files
+type outputArgs struct {
+	Out        string `arg:"-o,--out,required" help:"directory to write the articles to"`
+	Name       string `arg:"--name" default:"{{.Title}}.md" help:"template for the file names (fields: .Title, .Lang, .Revision, .TargetLang, .Index)"`
+	TargetLang string `arg:"-t,--target" help:"translate the articles in to this language"`
+	SourceLang string `arg:"-s,--" default:"" help:"source language, leave empty for autodetect"`
+	Wiki       string `arg:"--wiki,env:W2D_WIKI" default:"en" help:"language of the wikipedia to look up titles without language prefix"`
+
+	DeeplAuthKey string `arg:"-k,--,env:W2D_DEEPL_AUTH_KEY" help:"required with --target"`
+
+	parserArgs
+	translationArgs
+}
+
+type batchArgs struct {
+	List string `arg:"positional" default:"-" help:"file with one url or title (e.g. en:Hearth) per line or '-' for STDIN"`
+
+	outputArgs
+}
+
+type categoryArgs struct {
+	Category string `arg:"positional,required" help:"url or title of the category (e.g. \"Category:Cooking appliances\")"`
+	Depth    int    `arg:"--depth" default:"0" help:"levels of subcategories to include"`
+	Limit    int    `arg:"--limit" default:"0" help:"maximum number of articles, 0 for no limit"`
+
+	outputArgs
+}
+
+type crawlArgs struct {
+	Article string `arg:"positional,required" help:"url or title (e.g. en:Hearth) of the article to start at"`
+	Hops    int    `arg:"--hops" default:"1" help:"follow links up to this many articles away from the start article"`
+	Limit   int    `arg:"--limit" default:"100" help:"maximum number of articles, 0 for no limit"`
+
+	outputArgs
+}
+
+// validate rejects negative numbers, which are neither a depth nor a limit
+func (a *categoryArgs) validate() error {
+	if a.Depth < 0 {
+		return fmt.Errorf("--depth must not be negative, got %d", a.Depth)
+	}
+
+	return validateLimit(a.Limit)
+}
+
+// validate rejects negative numbers, which are neither a number of hops nor a limit
+func (a *crawlArgs) validate() error {
+	if a.Hops < 0 {
+		return fmt.Errorf("--hops must not be negative, got %d", a.Hops)
+	}
+
+	return validateLimit(a.Limit)
+}
+
+func validateLimit(limit int) error {
+	if limit < 0 {
+		return fmt.Errorf("--limit must not be negative (0 for no limit), got %d", limit)
+	}
+
+	return nil
+}
+
+// errBatchFailed is returned by the batch command if at least one article could not be converted
+var errBatchFailed = errors.New("batch failed")
+
+// batchFile holds the fields available in the file name template of the batch command
+type batchFile struct {
+	Title      string
+	Lang       string
+	Revision   int
+	TargetLang string
+	// Index is the number of the article in the list, starting at 1
+	Index int
+}
+
+// fileNameReplacer replaces characters which are not allowed in file names on common file systems
+var fileNameReplacer = strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+
+// readArticleList returns the non-empty lines of r which are not comments (#)
+func readArticleList(r io.Reader) ([]string, error) {
+	var refs []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		refs = append(refs, line)
+	}
+
+	return refs, scanner.Err()
+}
+
+// fileNamer builds the names of the output files of articles from a template. Every name is handed out only once.
+type fileNamer struct {
+	tmpl    *template.Template
+	outDir  string
+	tgtLang string
+	// written maps the handed out file names to the article they were handed out for
+	written map[string]string
+}
+
+func newFileNamer(outDir, name, tgtLang string) (*fileNamer, error) {
+	tmpl, err := template.New("name").Option("missingkey=error").Parse(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file name template: %s", err)
+	}
+
+	return &fileNamer{tmpl, outDir, tgtLang, map[string]string{}}, nil
+}
+
+// name returns the path of the output file for page, which was given as ref at index
+func (n *fileNamer) name(page *wikipedia.Page, ref string, index int) (string, error) {
+	var buf bytes.Buffer
+	err := n.tmpl.Execute(&buf, batchFile{fileNameReplacer.Replace(page.Title), page.Language, page.Revision, n.tgtLang, index})
+	if err != nil {
+		return "", fmt.Errorf("invalid file name template: %s", err)
+	}
+
+	file := filepath.Join(n.outDir, buf.String())
+	if other, ok := n.written[file]; ok {
+		return "", fmt.Errorf("file %s was already written for %s", file, other)
+	}
+	n.written[file] = ref
+
+	return file, nil
+}
+
+// writeArticle writes markdown to file, creating its directory if necessary
+func writeArticle(file, markdown string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, []byte(markdown), 0644)
+}
+
+// newBatchCmd returns cmd-function which converts every article in refs using convert and writes them in to outDir. The
+// summary of all successes and failures is returned, together with errBatchFailed if any article failed. convert is
+// given the file the article is written to.
+func newBatchCmd(wiki wikipedia.Client, convert func(page *wikipedia.Page, file string) (string, error)) func(refs []string, defaultLang, outDir, name, tgtLang string) (string, error) {
+	return func(refs []string, defaultLang, outDir, name, tgtLang string) (string, error) {
+		namer, err := newFileNamer(outDir, name, tgtLang)
+		if err != nil {
+			return "", err
+		}
+
+		summary := batchSummary{}
+		for i, ref := range refs {
+			file, err := func() (string, error) {
+				page, err := wiki.Fetch(ref, defaultLang, 0)
+				if err != nil {
+					return "", err
+				}
+
+				file, err := namer.name(page, ref, i+1)
+				if err != nil {
+					return "", err
+				}
+
+				markdown, err := convert(page, file)
+				if err != nil {
+					return "", err
+				}
+
+				return file, writeArticle(file, markdown)
+			}()
+
+			summary.add(ref, file, err)
+		}
+
+		return summary.result()
+	}
+}
+
+// batchSummary lists the outcome of every article of a command which converts multiple articles
+type batchSummary struct {
+	sb            strings.Builder
+	total, failed int
+}
+
+func (s *batchSummary) add(ref, file string, err error) {
+	s.total++
+	if err != nil {
+		s.failed++
+		s.sb.WriteString(fmt.Sprintf("FAIL %s: %s\n", ref, err))
+		return
+	}
+
+	s.sb.WriteString(fmt.Sprintf("OK   %s -> %s\n", ref, file))
+}
+
+// result returns the summary and errBatchFailed if any article failed
+func (s *batchSummary) result() (string, error) {
+	s.sb.WriteString(fmt.Sprintf("\n%d succeeded, %d failed\n", s.total-s.failed, s.failed))
+	if s.failed > 0 {
+		return s.sb.String(), errBatchFailed
+	}
+
+	return s.sb.String(), nil
+}
+
+// newCrawlCmd returns cmd-function which writes an article and the articles it links to (see wikipedia.Crawl) in to
+// outDir. Links between the written articles are rewritten to point at the local files. converter returns the function
+// converting a single page using the given additional parser options.
+func newCrawlCmd(wiki wikipedia.Client, crawlParser *wikipedia.ArticleParser, converter func(extra ...wikipedia.Option) (func(page *wikipedia.Page, file string) (string, error), error)) func(ref, defaultLang string, hops, limit int, outDir, name, tgtLang string) (string, error) {
+	return func(ref, defaultLang string, hops, limit int, outDir, name, tgtLang string) (string, error) {
+		// the links of a page are rewritten relative to its file, which is set before converting it
+		var res *wikipedia.CrawlResult
+		var file string
+		files := map[*wikipedia.CrawledPage]string{}
+		rewrite := func(target string) string {
+			linked := files[res.Lookup(target)]
+			if linked == "" {
+				return target
+			}
+
+			rel, err := filepath.Rel(filepath.Dir(file), linked)
+			if err != nil {
+				return target
+			}
+
+			link := relativeLink(rel)
+			if u, err := url.Parse(target); err == nil && u.Fragment != "" {
+				link += "#" + u.EscapedFragment()
+			}
+
+			return link
+		}
+
+		// the converter is created once (and fails before crawling if the articles can't be converted)
+		convert, err := converter(wikipedia.WithLinkRewriter(rewrite))
+		if err != nil {
+			return "", err
+		}
+
+		res, err = wikipedia.Crawl(wiki, crawlParser, ref, defaultLang, hops, limit)
+		if err != nil {
+			return "", err
+		}
+
+		namer, err := newFileNamer(outDir, name, tgtLang)
+		if err != nil {
+			return "", err
+		}
+
+		// the file names of all articles are needed before converting the first one to rewrite the links
+		for i, cp := range res.Pages {
+			if cp.Err == nil {
+				files[cp], cp.Err = namer.name(cp.Page, cp.Ref, i+1)
+			}
+		}
+
+		summary := batchSummary{}
+		for _, cp := range res.Pages {
+			if cp.Err != nil {
+				summary.add(cp.Ref, "", cp.Err)
+				continue
+			}
+
+			file = files[cp]
+			markdown, err := convert(cp.Page, file)
+			if err == nil {
+				err = writeArticle(file, markdown)
+			}
+			summary.add(cp.Ref, file, err)
+		}
+
+		return summary.result()
+	}
+}
+
+// relativeLink returns the markdown link target of the relative file path rel
+func relativeLink(rel string) string {
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	link := strings.Join(segments, "/")
+	// a colon in the first segment would be taken for a scheme
+	if strings.Contains(segments[0], ":") {
+		link = "./" + link
+	}
+
+	return link
+}
+
+// openList opens the article list of the batch command
+func openList(src string) (io.ReadCloser, error) {
+	if src != "-" {
+		return os.Open(src)
+	}
+
+	if !stdInAttached() {
+		return nil, errors.New("stdin redirection required if '-' is given")
+	}
+
+	return os.Stdin, nil
+}
+
+// converter returns the function which turns a fetched page in to the content of its output file
+func (a *outputArgs) converter(extra ...wikipedia.Option) (func(page *wikipedia.Page, file string) (string, error), error) {
+	if a.TargetLang == "" {
+		markdown := newMarkdownCmd(wikipedia.NewArticleParser(append(a.options(), extra...)...))
+		return func(page *wikipedia.Page, file string) (string, error) {
+			out, err := markdown(page)
+			if err != nil {
+				return "", err
+			}
+
+			return a.postProcess(out, file)
+		}, nil
+	}
+
+	if a.DeeplAuthKey == "" {
+		return nil, errors.New("an auth key (-k) is required to translate")
+	}
+
+	opts := append(append(a.options(), extra...), wikipedia.WithTranslation(a.SourceLang, a.TargetLang, "deepl"))
+	translate := newTranslateCmd(wikipedia.NewArticleParser(opts...), deepl.NewClient(a.DeeplAuthKey), a.translationArgs)
+	return func(page *wikipedia.Page, file string) (string, error) {
+		out, err := translate(page, a.TargetLang, a.SourceLang)
+		if err != nil {
+			return "", err
+		}
+
+		return a.postProcess(out, file)
+	}, nil
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"github.com/IljaN/w2d/wikipedia"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fakeWikiURL = "https://en.wikipedia.org/wiki/"
+
+// fakeWiki is a wikipedia.Client serving the html of its pages by title
+type fakeWiki map[string]string
+
+func (w fakeWiki) Fetch(ref, defaultLang string, revision int) (*wikipedia.Page, error) {
+	title := strings.TrimPrefix(ref, "en:")
+	if strings.HasPrefix(ref, fakeWikiURL) {
+		title = wikipedia.NormalizeTitle(strings.TrimPrefix(ref, fakeWikiURL))
+	}
+
+	html, ok := w[title]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return &wikipedia.Page{
+		Title:    title,
+		URL:      fakeWikiURL + url.PathEscape(strings.ReplaceAll(title, " ", "_")),
+		Language: "en",
+		Revision: 1,
+		HTML:     "<div class=\"mw-parser-output\">" + html + "</div>",
+	}, nil
+}
+
+func (w fakeWiki) LangLinks(ref, defaultLang string) ([]wikipedia.LangLink, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (w fakeWiki) CategoryMembers(ref, defaultLang string, depth, limit int) ([]wikipedia.CategoryMember, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestCrawlCmd(t *testing.T) {
+	wiki := fakeWiki{
+		"Hearth": "<p><a href=\"/wiki/C%23\">c sharp</a> <a href=\"/wiki/100%25_Fire\">all fire</a> " +
+			"<a href=\"/wiki/Fire_(element)\">element</a> <a href=\"/wiki/C%23#History\">history</a></p>",
+		"C#":             "<p><a href=\"/wiki/Hearth\">hearth</a> <a href=\"/wiki/Ash\">ash</a></p>",
+		"100% Fire":      "<p><a href=\"/wiki/Hearth\">hearth</a></p>",
+		"Fire (element)": "<p>fire</p>",
+		"Ash":            "<p>ash</p>",
+	}
+
+	converted := 0
+	converter := func(extra ...wikipedia.Option) (func(page *wikipedia.Page, file string) (string, error), error) {
+		converted++
+		opts := append([]wikipedia.Option{wikipedia.WithLinkMode(wikipedia.LinkInline)}, extra...)
+		markdown := newMarkdownCmd(wikipedia.NewArticleParser(opts...))
+		return func(page *wikipedia.Page, file string) (string, error) {
+			return markdown(page)
+		}, nil
+	}
+
+	tests := map[string]struct {
+		name string
+		// exp maps the written files to the links they must contain
+		exp map[string][]string
+	}{
+		"same_dir": {
+			name: "{{.Title}}.md",
+			exp: map[string][]string{
+				"Hearth.md":         {"[c sharp](C%23.md)", "[all fire](100%25%20Fire.md)", "[element](Fire%20%28element%29.md)", "[history](C%23.md#History)"},
+				"C#.md":             {"[hearth](Hearth.md)", "[ash](" + fakeWikiURL + "Ash)"},
+				"100% Fire.md":      {"[hearth](Hearth.md)"},
+				"Fire (element).md": {"fire"},
+			},
+		},
+		"sub_dirs": {
+			name: "{{.Index}}/{{.Title}}.md",
+			exp: map[string][]string{
+				"1/Hearth.md": {"[c sharp](../2/C%23.md)", "[all fire](../3/100%25%20Fire.md)"},
+				"2/C#.md":     {"[hearth](../1/Hearth.md)"},
+			},
+		},
+		"colon": {
+			name: "{{.Lang}}:{{.Title}}.md",
+			exp: map[string][]string{
+				"en:Hearth.md": {"[c sharp](./en:C%23.md)"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out := t.TempDir()
+			converted = 0
+			crawl := newCrawlCmd(wiki, wikipedia.NewArticleParser(), converter)
+			summary, err := crawl("en:Hearth", "en", 1, 0, out, tc.name, "")
+			assert.NoError(t, err)
+			assert.Contains(t, summary, "4 succeeded, 0 failed")
+			assert.Equal(t, 1, converted, "the converter should be created once")
+
+			for file, links := range tc.exp {
+				markdown, err := os.ReadFile(filepath.Join(out, file))
+				if !assert.NoError(t, err) {
+					continue
+				}
+				for _, link := range links {
+					assert.Contains(t, string(markdown), link, file)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchCmd(t *testing.T) {
+	wiki := fakeWiki{
+		"Hearth": "<p>hearth</p>",
+		"Fire":   "<p>fire</p>",
+		"Broken": "<p>broken</p>",
+	}
+
+	convert := func(page *wikipedia.Page, file string) (string, error) {
+		if page.Title == "Broken" {
+			return "", errors.New("failed to parse")
+		}
+
+		return newMarkdownCmd(wikipedia.NewArticleParser())(page)
+	}
+
+	tests := map[string]struct {
+		refs []string
+		name string
+		// exp is the summary with {out} replacing the output directory
+		exp   string
+		err   error
+		files []string
+	}{
+		"all_success": {
+			refs:  []string{"en:Hearth", fakeWikiURL + "Fire"},
+			name:  "{{.Title}}.md",
+			exp:   "OK   en:Hearth -> {out}/Hearth.md\nOK   " + fakeWikiURL + "Fire -> {out}/Fire.md\n\n2 succeeded, 0 failed\n",
+			files: []string{"Hearth.md", "Fire.md"},
+		},
+		"partial_failure": {
+			refs:  []string{"en:Hearth", "en:Missing"},
+			name:  "{{.Index}}-{{.Title}}.md",
+			exp:   "OK   en:Hearth -> {out}/1-Hearth.md\nFAIL en:Missing: not found\n\n1 succeeded, 1 failed\n",
+			err:   errBatchFailed,
+			files: []string{"1-Hearth.md"},
+		},
+		"convert_failure": {
+			refs:  []string{"en:Broken", "en:Fire"},
+			name:  "{{.Title}}.md",
+			exp:   "FAIL en:Broken: failed to parse\nOK   en:Fire -> {out}/Fire.md\n\n1 succeeded, 1 failed\n",
+			err:   errBatchFailed,
+			files: []string{"Fire.md"},
+		},
+		"same_file": {
+			refs:  []string{"en:Hearth", "Hearth"},
+			name:  "{{.Lang}}.md",
+			exp:   "OK   en:Hearth -> {out}/en.md\nFAIL Hearth: file {out}/en.md was already written for en:Hearth\n\n1 succeeded, 1 failed\n",
+			err:   errBatchFailed,
+			files: []string{"en.md"},
+		},
+		"all_failed": {
+			refs: []string{"en:Missing"},
+			name: "{{.Title}}.md",
+			exp:  "FAIL en:Missing: not found\n\n0 succeeded, 1 failed\n",
+			err:  errBatchFailed,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out := t.TempDir()
+			batch := newBatchCmd(wiki, convert)
+			summary, err := batch(tc.refs, "en", out, tc.name, "")
+
+			if tc.err != nil {
+				assert.True(t, errors.Is(err, tc.err), "expected %v, got %v", tc.err, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, strings.ReplaceAll(tc.exp, "{out}", out), summary)
+
+			entries, err := os.ReadDir(out)
+			assert.NoError(t, err)
+			var files []string
+			for _, e := range entries {
+				files = append(files, e.Name())
+			}
+			assert.ElementsMatch(t, tc.files, files)
+		})
+	}
+}
+
+func TestBatchCmdImages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("png"))
+	}))
+	defer srv.Close()
+
+	wiki := fakeWiki{
+		"Fire": "<figure typeof=\"mw:File/Thumb\"><a href=\"./File:Fire.png\"><img alt=\"Fire\" src=\"" + srv.URL + "/Fire.png\"></a></figure>",
+	}
+
+	tmp := t.TempDir()
+	args := outputArgs{parserArgs: parserArgs{ImageDir: filepath.Join(tmp, "images")}}
+	convert, err := args.converter()
+	assert.NoError(t, err)
+
+	out := filepath.Join(tmp, "out")
+	_, err = newBatchCmd(wiki, convert)([]string{"en:Fire"}, "en", out, "{{.Lang}}/{{.Title}}.md", "")
+	assert.NoError(t, err)
+
+	markdown, err := os.ReadFile(filepath.Join(out, "en", "Fire.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(markdown), "![Fire](../../images/Fire.png)")
+	assert.FileExists(t, filepath.Join(tmp, "images", "Fire.png"))
+}
+
+func TestValidateNegative(t *testing.T) {
+	tests := map[string]struct {
+		validate func() error
+		err      string
+	}{
+		"category":       {validate: (&categoryArgs{Depth: 2, Limit: 10}).validate},
+		"category_depth": {validate: (&categoryArgs{Depth: -1}).validate, err: "--depth must not be negative"},
+		"category_limit": {validate: (&categoryArgs{Limit: -5}).validate, err: "--limit must not be negative"},
+		"crawl":          {validate: (&crawlArgs{Hops: 0, Limit: 0}).validate},
+		"crawl_hops":     {validate: (&crawlArgs{Hops: -1, Limit: 100}).validate, err: "--hops must not be negative"},
+		"crawl_limit":    {validate: (&crawlArgs{Hops: 1, Limit: -1}).validate, err: "--limit must not be negative"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.validate()
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package deepl
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -16,12 +17,31 @@ import (
 type Client interface {
 	// Translate the given text from sourceLang to targetLang. Set sourceLang to "" (empty-string) to use automatic source-
 	// language detection. Use the SupportedLanguages method to query possible values for targetLang and sourceLang.
-	Translate(text, targetLang, sourceLang string) ([]string, error)
+	// Large texts are split and translated in multiple requests, the parts are returned in order.
+	Translate(text, targetLang, sourceLang string, opts ...TranslateOption) ([]string, error)
 	// TranslateToString same as Translate but returns the concatenated text
-	TranslateToString(text, targetLang, sourceLang string) (string, error)
+	TranslateToString(text, targetLang, sourceLang string, opts ...TranslateOption) (string, error)
+	// TranslateMarkdown translates markdown while keeping its structure, links and code intact
+	TranslateMarkdown(markdown, targetLang, sourceLang string, opts ...TranslateOption) (string, error)
 	// SupportedLanguages returns the list of supported source languages if target is set to false. Otherwise,
 	// the supported target languages are returned.
 	SupportedLanguages(target bool) (map[string]SupportedLanguage, error)
+
+	// CreateGlossary creates a glossary from entries in the given format (GlossaryTSV or GlossaryCSV)
+	CreateGlossary(name, sourceLang, targetLang, entries, format string) (Glossary, error)
+	// Glossaries returns all glossaries of the account
+	Glossaries() ([]Glossary, error)
+	// Glossary returns the glossary with the given id
+	Glossary(id string) (Glossary, error)
+	// GlossaryEntries returns the entries of the glossary with the given id
+	GlossaryEntries(id string) ([]GlossaryEntry, error)
+	// DeleteGlossary deletes the glossary with the given id
+	DeleteGlossary(id string) error
+	// GlossaryLanguagePairs returns the combinations of languages glossaries can be created for
+	GlossaryLanguagePairs() ([]GlossaryLanguagePair, error)
+
+	// Usage returns the number of characters translated in the current billing period and the limit
+	Usage() (Usage, error)
 }
 
 const (
@@ -29,17 +49,90 @@ const (
 	FreeEndpoint = "https://api-free.deepl.com/v2/"
 )
 
+const (
+	// MaxRequestSize is the maximum size of a request body accepted by the api
+	MaxRequestSize = 128 * 1024
+	// MaxTexts is the maximum number of text parameters in a single translate request
+	MaxTexts = 50
+)
+
 type client struct {
 	Endpoint string
 	AuthKey  string
 	client   *http.Client
+	// maxRequestSize limits the size of the requests, MaxRequestSize is used if it is 0
+	maxRequestSize int
+	// targetLanguages caches the supported target languages
+	targetLanguages map[string]SupportedLanguage
 }
 
 func NewClient(authKey string) Client {
 	return &client{
-		Endpoint: DetermineEndpoint(authKey),
-		AuthKey:  authKey,
-		client:   http.DefaultClient,
+		Endpoint:       DetermineEndpoint(authKey),
+		AuthKey:        authKey,
+		client:         http.DefaultClient,
+		maxRequestSize: MaxRequestSize,
+	}
+}
+
+// TranslateOption sets optional parameters of a translate request
+type TranslateOption func(params url.Values)
+
+func withParam(key, value string) TranslateOption {
+	return func(params url.Values) {
+		params.Set(key, value)
+	}
+}
+
+// WithTagHandling enables the translation of XML or HTML ("xml" or "html")
+func WithTagHandling(tagHandling string) TranslateOption {
+	return withParam("tag_handling", tagHandling)
+}
+
+// WithIgnoreTags excludes the content of the given XML tags from translation
+func WithIgnoreTags(tags ...string) TranslateOption {
+	return withParam("ignore_tags", strings.Join(tags, ","))
+}
+
+// WithNonSplittingTags sets the XML tags which never split sentences
+func WithNonSplittingTags(tags ...string) TranslateOption {
+	return withParam("non_splitting_tags", strings.Join(tags, ","))
+}
+
+// WithSplittingTags sets the XML tags which always split sentences
+func WithSplittingTags(tags ...string) TranslateOption {
+	return withParam("splitting_tags", strings.Join(tags, ","))
+}
+
+// Formality sets whether the translation should lean towards formal or informal language
+type Formality string
+
+const (
+	FormalityDefault    Formality = "default"
+	FormalityMore       Formality = "more"
+	FormalityLess       Formality = "less"
+	FormalityPreferMore Formality = "prefer_more"
+	FormalityPreferLess Formality = "prefer_less"
+)
+
+// UnmarshalText parses the formality from its name, which allows using Formality directly as cli argument.
+func (f *Formality) UnmarshalText(text []byte) error {
+	switch formality := Formality(text); formality {
+	case FormalityDefault, FormalityMore, FormalityLess, FormalityPreferMore, FormalityPreferLess:
+		*f = formality
+		return nil
+	}
+
+	return fmt.Errorf("invalid formality: %s (expected default, more, less, prefer_more or prefer_less)", text)
+}
+
+// WithFormality sets the formality of the translation. more and less are only supported by some target languages (see
+// SupportedLanguage.SupportsFormality), prefer_more and prefer_less fall back to the default for the others.
+func WithFormality(formality Formality) TranslateOption {
+	return func(params url.Values) {
+		if formality != "" {
+			params.Set("formality", string(formality))
+		}
 	}
 }
 
@@ -51,8 +144,8 @@ type TranslateResponse struct {
 }
 
 // TranslateToString is a helper which calls Translate and concatenates the result in to a single string
-func (c *client) TranslateToString(text, targetLang, sourceLang string) (string, error) {
-	s, err := c.Translate(text, targetLang, sourceLang)
+func (c *client) TranslateToString(text, targetLang, sourceLang string, opts ...TranslateOption) (string, error) {
+	s, err := c.Translate(text, targetLang, sourceLang, opts...)
 	if err != nil {
 		return "", err
 	}
@@ -69,24 +162,80 @@ func (c *client) TranslateToString(text, targetLang, sourceLang string) (string,
 
 // Translate the given text from sourceLang to targetLang. Set sourceLang to "" (empty-string) to use automatic source-
 // language detection. Use the SupportedLanguages method to query possible values for targetLang and sourceLang.
-func (c *client) Translate(text, targetLang, sourceLang string) ([]string, error) {
+//
+// Texts exceeding the request size limit of the api are split at paragraph (or line) boundaries and translated in
+// multiple requests. The translated parts are returned in order, including the whitespace between them.
+func (c *client) Translate(text, targetLang, sourceLang string, opts ...TranslateOption) ([]string, error) {
 	params := url.Values{}
 	params.Add("auth_key", c.AuthKey)
 	params.Add("target_lang", targetLang)
-	params.Add("text", text)
 	if sourceLang != "" {
 		params.Add("source_lang", sourceLang)
 	}
+	for _, opt := range opts {
+		opt(params)
+	}
+
+	if err := c.validateFormality(Formality(params.Get("formality")), targetLang); err != nil {
+		return []string{}, err
+	}
+	if params.Get("glossary_id") != "" && sourceLang == "" {
+		return []string{}, errors.New("a source language is required to translate with a glossary")
+	}
+
+	maxSize := c.maxRequestSize
+	if maxSize == 0 {
+		maxSize = MaxRequestSize
+	}
+	// every text is added as &text=...
+	maxSize -= len(params.Encode()) + len("&text=")
+
+	chunks := splitText(text, maxSize)
+	r := make([]string, len(chunks))
+	for _, batch := range batchChunks(chunks, maxSize) {
+		batchParams := url.Values{}
+		for k, v := range params {
+			batchParams[k] = v
+		}
+		for _, i := range batch {
+			batchParams.Add("text", chunks[i].text)
+		}
 
+		translations, err := c.translate(batchParams)
+		if err != nil {
+			return []string{}, err
+		}
+		if len(translations) != len(batch) {
+			return []string{}, fmt.Errorf("expected %d translations, got %d", len(batch), len(translations))
+		}
+
+		for k, i := range batch {
+			r[i] = translations[k]
+		}
+	}
+
+	for i, chunk := range chunks {
+		r[i] = chunk.leading + r[i] + chunk.trailing
+	}
+
+	return r, nil
+}
+
+// translate sends a single translate request
+func (c *client) translate(params url.Values) ([]string, error) {
 	ep := c.Endpoint + "translate"
 	resp, err := c.client.PostForm(ep, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
 	if err := validateResponse(resp); err != nil {
-		return []string{}, err
+		return nil, err
 	}
 	parsed, err := parseResponse[TranslateResponse](resp)
 	if err != nil {
-		return []string{}, err
+		return nil, err
 	}
 	r := []string{}
 	for _, translated := range parsed.Translations {
@@ -95,6 +244,31 @@ func (c *client) Translate(text, targetLang, sourceLang string) ([]string, error
 	return r, nil
 }
 
+// validateFormality checks if targetLang supports formality, so that no characters are wasted on a request which fails
+func (c *client) validateFormality(formality Formality, targetLang string) error {
+	switch formality {
+	case "", FormalityDefault, FormalityPreferMore, FormalityPreferLess:
+		return nil
+	case FormalityMore, FormalityLess:
+	default:
+		return fmt.Errorf("invalid formality: %s", formality)
+	}
+
+	if c.targetLanguages == nil {
+		langs, err := c.SupportedLanguages(true)
+		if err != nil {
+			return fmt.Errorf("failed to check formality support: %s", err)
+		}
+		c.targetLanguages = langs
+	}
+
+	if lang, ok := c.targetLanguages[strings.ToUpper(targetLang)]; !ok || !lang.SupportsFormality {
+		return fmt.Errorf("target language %s does not support formality %s, use prefer_%s instead", targetLang, formality, formality)
+	}
+
+	return nil
+}
+
 type SupportedLanguageResponse []SupportedLanguage
 
 type SupportedLanguage struct {
@@ -115,6 +289,10 @@ func (c *client) SupportedLanguages(target bool) (map[string]SupportedLanguage,
 	}
 
 	resp, err := c.client.PostForm(ep, params)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
 	if err := validateResponse(resp); err != nil {
 		return nil, err
@@ -134,6 +312,40 @@ func (c *client) SupportedLanguages(target bool) (map[string]SupportedLanguage,
 	return supportedLangs, nil
 }
 
+// Usage is the number of characters translated in the current billing period
+type Usage struct {
+	CharacterCount int64 `json:"character_count"`
+	CharacterLimit int64 `json:"character_limit"`
+}
+
+// Remaining returns the number of characters which can still be translated in the current billing period
+func (u Usage) Remaining() int64 {
+	if u.CharacterCount >= u.CharacterLimit {
+		return 0
+	}
+
+	return u.CharacterLimit - u.CharacterCount
+}
+
+// Usage returns the number of characters translated in the current billing period and the limit
+func (c *client) Usage() (Usage, error) {
+	ep := c.Endpoint + "usage"
+	params := url.Values{}
+	params.Add("auth_key", c.AuthKey)
+
+	resp, err := c.client.PostForm(ep, params)
+	if err != nil {
+		return Usage{}, err
+	}
+	defer resp.Body.Close()
+
+	if err := validateResponse(resp); err != nil {
+		return Usage{}, err
+	}
+
+	return parseResponse[Usage](resp)
+}
+
 var KnownErrors = map[int]string{
 	400: "Bad request. Please check error message and your parameters.",
 	403: "Authorization failed. Please supply a valid auth_key parameter.",
@@ -215,3 +215,148 @@ func TestEndpointForAuthKey(t *testing.T) {
 		t.Fatalf("Expected endpoint to be free (%s), got %s", exp, got)
 	}
 }
+
+func TestTranslateSplitsLargeTexts(t *testing.T) {
+	requests := 0
+	c := client{
+		Endpoint:       ProEndpoint,
+		AuthKey:        "abc",
+		maxRequestSize: 60,
+		client: NewTestClient(func(req *http.Request) *http.Response {
+			requests++
+			_ = req.ParseForm()
+			if size := len(req.Form.Encode()); size > 60 {
+				t.Fatalf("Request size %d exceeds the limit of 60", size)
+			}
+
+			var translations []map[string]string
+			for _, text := range req.Form["text"] {
+				translations = append(translations, map[string]string{"text": strings.ToUpper(text)})
+			}
+			body, _ := json.Marshal(map[string]interface{}{"translations": translations})
+
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(body)),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	text := "# Hearth\n\nThe hearth is a place.\n\n## History\n\nIt was used for cooking.\n\n"
+	translated, err := c.TranslateToString(text, "ru", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if exp := strings.ToUpper(text); translated != exp {
+		t.Fatalf("Expected %q, got %q", exp, translated)
+	}
+	if requests < 2 {
+		t.Fatalf("Expected text to be split in to multiple requests, got %d", requests)
+	}
+}
+
+func TestTranslateFormality(t *testing.T) {
+	var languageRequests, translateRequests int
+	c := client{
+		Endpoint: ProEndpoint,
+		AuthKey:  "abc",
+		client: NewTestClient(func(req *http.Request) *http.Response {
+			_ = req.ParseForm()
+			body := `[{"language":"DE","name":"German","supports_formality":true},{"language":"EN-GB","name":"English (British)","supports_formality":false}]`
+			if req.URL.String() == ProEndpoint+"translate" {
+				translateRequests++
+				body = `{"translations":[{"text":"Hallo"}]}`
+			} else {
+				languageRequests++
+			}
+
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	tests := map[string]struct {
+		formality  Formality
+		targetLang string
+		err        string
+	}{
+		"none":                 {targetLang: "en-gb"},
+		"more":                 {formality: FormalityMore, targetLang: "de"},
+		"less":                 {formality: FormalityLess, targetLang: "DE"},
+		"prefer_unsupported":   {formality: FormalityPreferLess, targetLang: "EN-GB"},
+		"unsupported":          {formality: FormalityMore, targetLang: "EN-GB", err: "does not support formality"},
+		"unsupported_language": {formality: FormalityLess, targetLang: "XX", err: "does not support formality"},
+		"invalid":              {formality: "very", targetLang: "DE", err: "invalid formality"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			translateRequests = 0
+			_, err := c.Translate("Hello", tc.targetLang, "", WithFormality(tc.formality))
+			if tc.err == "" {
+				if err != nil || translateRequests != 1 {
+					t.Fatalf("Expected a single translate request without error, got %d requests and error %v", translateRequests, err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("Expected error containing %q, got %v", tc.err, err)
+			}
+			if translateRequests != 0 {
+				t.Fatalf("No translate request should be sent if the formality is not supported")
+			}
+		})
+	}
+
+	if languageRequests != 1 {
+		t.Fatalf("Expected supported languages to be requested once, got %d requests", languageRequests)
+	}
+}
+
+func TestFormalityUnmarshalText(t *testing.T) {
+	var f Formality
+	if err := f.UnmarshalText([]byte("prefer_more")); err != nil || f != FormalityPreferMore {
+		t.Fatalf("Expected prefer_more to be parsed, got %s (error %v)", f, err)
+	}
+
+	if err := f.UnmarshalText([]byte("formal")); err == nil {
+		t.Fatalf("Expected an error for an invalid formality")
+	}
+}
+
+func TestUsage(t *testing.T) {
+	c := client{
+		Endpoint: ProEndpoint,
+		AuthKey:  "abc",
+		client: NewTestClient(func(req *http.Request) *http.Response {
+			if req.URL.String() != ProEndpoint+"usage" {
+				t.Fatalf("Url %s expected, got %s", ProEndpoint+"usage", req.URL.String())
+			}
+
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"character_count":499000,"character_limit":500000}`)),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	usage, err := c.Usage()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	if usage.CharacterCount != 499000 || usage.CharacterLimit != 500000 || usage.Remaining() != 1000 {
+		t.Fatalf("Unexpected usage %+v (remaining %d)", usage, usage.Remaining())
+	}
+
+	if remaining := (Usage{CharacterCount: 600, CharacterLimit: 500}).Remaining(); remaining != 0 {
+		t.Fatalf("Expected no remaining characters if the limit is exceeded, got %d", remaining)
+	}
+}
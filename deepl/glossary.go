@@ -0,0 +1,224 @@
+package deepl
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Glossary holds terms which are always translated the same way, see Client.CreateGlossary
+type Glossary struct {
+	ID           string `json:"glossary_id"`
+	Name         string `json:"name"`
+	Ready        bool   `json:"ready"`
+	SourceLang   string `json:"source_lang"`
+	TargetLang   string `json:"target_lang"`
+	CreationTime string `json:"creation_time"`
+	EntryCount   int    `json:"entry_count"`
+}
+
+// GlossaryEntry is a term in the source language and its translation
+type GlossaryEntry struct {
+	Source, Target string
+}
+
+// GlossaryLanguagePair is a combination of languages glossaries can be created for
+type GlossaryLanguagePair struct {
+	SourceLang string `json:"source_lang"`
+	TargetLang string `json:"target_lang"`
+}
+
+// Formats of the entries of a glossary
+const (
+	GlossaryTSV = "tsv"
+	GlossaryCSV = "csv"
+)
+
+// WithGlossary translates using the glossary with the given id. The source language has to be set and match the one of
+// the glossary.
+func WithGlossary(id string) TranslateOption {
+	return func(params url.Values) {
+		if id != "" {
+			params.Set("glossary_id", id)
+		}
+	}
+}
+
+// CreateGlossary creates a glossary from entries in the given format (GlossaryTSV or GlossaryCSV) with one term and its
+// translation per line
+func (c *client) CreateGlossary(name, sourceLang, targetLang, entries, format string) (Glossary, error) {
+	params := url.Values{}
+	params.Add("name", name)
+	params.Add("source_lang", sourceLang)
+	params.Add("target_lang", targetLang)
+	params.Add("entries", entries)
+	params.Add("entries_format", format)
+
+	resp, err := c.glossaryRequest(http.MethodPost, "glossaries", params)
+	if err != nil {
+		return Glossary{}, err
+	}
+	defer resp.Body.Close()
+
+	return parseResponse[Glossary](resp)
+}
+
+// Glossaries returns all glossaries of the account
+func (c *client) Glossaries() ([]Glossary, error) {
+	resp, err := c.glossaryRequest(http.MethodGet, "glossaries", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	parsed, err := parseResponse[struct {
+		Glossaries []Glossary `json:"glossaries"`
+	}](resp)
+
+	return parsed.Glossaries, err
+}
+
+// Glossary returns the glossary with the given id
+func (c *client) Glossary(id string) (Glossary, error) {
+	resp, err := c.glossaryRequest(http.MethodGet, "glossaries/"+url.PathEscape(id), nil)
+	if err != nil {
+		return Glossary{}, err
+	}
+	defer resp.Body.Close()
+
+	return parseResponse[Glossary](resp)
+}
+
+// GlossaryEntries returns the entries of the glossary with the given id
+func (c *client) GlossaryEntries(id string) ([]GlossaryEntry, error) {
+	resp, err := c.glossaryRequest(http.MethodGet, "glossaries/"+url.PathEscape(id)+"/entries", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	tsv, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGlossaryEntries(string(tsv), GlossaryTSV)
+}
+
+// DeleteGlossary deletes the glossary with the given id
+func (c *client) DeleteGlossary(id string) error {
+	resp, err := c.glossaryRequest(http.MethodDelete, "glossaries/"+url.PathEscape(id), nil)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// GlossaryLanguagePairs returns the combinations of languages glossaries can be created for
+func (c *client) GlossaryLanguagePairs() ([]GlossaryLanguagePair, error) {
+	resp, err := c.glossaryRequest(http.MethodGet, "glossary-language-pairs", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	parsed, err := parseResponse[struct {
+		SupportedLanguages []GlossaryLanguagePair `json:"supported_languages"`
+	}](resp)
+
+	return parsed.SupportedLanguages, err
+}
+
+// glossaryRequest sends a request to one of the glossary endpoints. Parameters of POST requests are sent as form,
+// otherwise they are added to the query.
+func (c *client) glossaryRequest(method, path string, params url.Values) (*http.Response, error) {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Add("auth_key", c.AuthKey)
+
+	ep := c.Endpoint + path
+	var resp *http.Response
+	var err error
+	if method == http.MethodPost {
+		resp, err = c.client.PostForm(ep, params)
+	} else {
+		var req *http.Request
+		req, err = http.NewRequest(method, ep+"?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = c.client.Do(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// ParseGlossaryEntries parses entries in the given format (GlossaryTSV or GlossaryCSV). Empty lines are skipped.
+func ParseGlossaryEntries(entries, format string) ([]GlossaryEntry, error) {
+	switch format {
+	case GlossaryTSV:
+		return parseTSVEntries(entries)
+	case GlossaryCSV:
+		return parseCSVEntries(entries)
+	}
+
+	return nil, fmt.Errorf("invalid glossary format: %s (expected tsv or csv)", format)
+}
+
+// parseTSVEntries splits the lines of entries at tabs. TSV has no quoting, quotes are part of the terms.
+func parseTSVEntries(entries string) ([]GlossaryEntry, error) {
+	var parsed []GlossaryEntry
+	for i, line := range strings.Split(entries, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			return nil, invalidEntryError(i + 1)
+		}
+		parsed = append(parsed, GlossaryEntry{Source: fields[0], Target: fields[1]})
+	}
+
+	return parsed, nil
+}
+
+func parseCSVEntries(entries string) ([]GlossaryEntry, error) {
+	r := csv.NewReader(strings.NewReader(entries))
+	r.FieldsPerRecord = -1
+
+	var parsed []GlossaryEntry
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return parsed, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid glossary entries: %s", err)
+		}
+
+		if len(record) < 2 {
+			line, _ := r.FieldPos(0)
+			return nil, invalidEntryError(line)
+		}
+		parsed = append(parsed, GlossaryEntry{Source: record[0], Target: record[1]})
+	}
+}
+
+func invalidEntryError(line int) error {
+	return fmt.Errorf("invalid glossary entry in line %d: expected a term and its translation", line)
+}
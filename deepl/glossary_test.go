@@ -0,0 +1,121 @@
+package deepl
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGlossaryEndpoints(t *testing.T) {
+	const glossary = `{"glossary_id":"g1","name":"Fire","ready":true,"source_lang":"en","target_lang":"de","creation_time":"2022-01-01T00:00:00Z","entry_count":2}`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("auth_key") != "abc" {
+			t.Fatalf("Parameter auth_key missing in %s %s", r.Method, r.URL.Path)
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /glossaries":
+			if r.Form.Get("entries") != "hearth\tHerd" || r.Form.Get("entries_format") != "tsv" || r.Form.Get("source_lang") != "en" {
+				t.Fatalf("Unexpected parameters %v", r.Form)
+			}
+			_, _ = fmt.Fprint(w, glossary)
+		case "GET /glossaries":
+			_, _ = fmt.Fprint(w, `{"glossaries":[`+glossary+`]}`)
+		case "GET /glossaries/g1":
+			_, _ = fmt.Fprint(w, glossary)
+		case "GET /glossaries/g1/entries":
+			_, _ = fmt.Fprint(w, "hearth\tHerd\nfire\tFeuer")
+		case "DELETE /glossaries/g1":
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /glossary-language-pairs":
+			_, _ = fmt.Fprint(w, `{"supported_languages":[{"source_lang":"de","target_lang":"en"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"message":"Glossary not found"}`)
+		}
+	}))
+	defer srv.Close()
+
+	c := client{Endpoint: srv.URL + "/", AuthKey: "abc", client: srv.Client()}
+	exp := Glossary{ID: "g1", Name: "Fire", Ready: true, SourceLang: "en", TargetLang: "de", CreationTime: "2022-01-01T00:00:00Z", EntryCount: 2}
+
+	created, err := c.CreateGlossary("Fire", "en", "de", "hearth\tHerd", GlossaryTSV)
+	if err != nil || created != exp {
+		t.Fatalf("Expected glossary %v, got %v (error %v)", exp, created, err)
+	}
+
+	list, err := c.Glossaries()
+	if err != nil || !reflect.DeepEqual(list, []Glossary{exp}) {
+		t.Fatalf("Expected glossaries %v, got %v (error %v)", []Glossary{exp}, list, err)
+	}
+
+	g, err := c.Glossary("g1")
+	if err != nil || g != exp {
+		t.Fatalf("Expected glossary %v, got %v (error %v)", exp, g, err)
+	}
+
+	entries, err := c.GlossaryEntries("g1")
+	expEntries := []GlossaryEntry{{"hearth", "Herd"}, {"fire", "Feuer"}}
+	if err != nil || !reflect.DeepEqual(entries, expEntries) {
+		t.Fatalf("Expected entries %v, got %v (error %v)", expEntries, entries, err)
+	}
+
+	if err := c.DeleteGlossary("g1"); err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	pairs, err := c.GlossaryLanguagePairs()
+	if err != nil || !reflect.DeepEqual(pairs, []GlossaryLanguagePair{{"de", "en"}}) {
+		t.Fatalf("Unexpected language pairs %v (error %v)", pairs, err)
+	}
+
+	if _, err := c.Glossary("missing"); err == nil {
+		t.Fatalf("Expected an error for a missing glossary")
+	}
+}
+
+func TestParseGlossaryEntries(t *testing.T) {
+	tests := map[string]struct {
+		entries, format string
+		exp             []GlossaryEntry
+		err             bool
+	}{
+		"tsv":            {entries: "hearth\tHerd\n\nfire\tFeuer\n", format: GlossaryTSV, exp: []GlossaryEntry{{"hearth", "Herd"}, {"fire", "Feuer"}}},
+		"csv":            {entries: "hearth,Herd\n\"fire, open\",offenes Feuer", format: GlossaryCSV, exp: []GlossaryEntry{{"hearth", "Herd"}, {"fire, open", "offenes Feuer"}}},
+		"tsv_quotes":     {entries: "\"hearth\tHerd\"\r\n\"fire\" place\t\"Feuer\"stelle\r\n", format: GlossaryTSV, exp: []GlossaryEntry{{"\"hearth", "Herd\""}, {"\"fire\" place", "\"Feuer\"stelle"}}},
+		"missing_target": {entries: "hearth\tHerd\nfire", format: GlossaryTSV, err: true},
+		"csv_missing":    {entries: "hearth,Herd\nfire", format: GlossaryCSV, err: true},
+		"invalid_format": {entries: "hearth\tHerd", format: "xlsx", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			entries, err := ParseGlossaryEntries(tc.entries, tc.format)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("Expected an error, got %v", entries)
+				}
+				return
+			}
+
+			if err != nil || !reflect.DeepEqual(entries, tc.exp) {
+				t.Fatalf("Expected %v, got %v (error %v)", tc.exp, entries, err)
+			}
+		})
+	}
+}
+
+func TestTranslateGlossaryRequiresSourceLang(t *testing.T) {
+	c := client{Endpoint: ProEndpoint, AuthKey: "abc", client: NewTestClient(func(req *http.Request) *http.Response {
+		t.Fatalf("No request should be sent without source language")
+		return nil
+	})}
+
+	if _, err := c.Translate("Hello", "DE", "", WithGlossary("g1")); err == nil {
+		t.Fatalf("Expected an error without source language")
+	}
+}
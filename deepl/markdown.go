@@ -0,0 +1,293 @@
+package deepl
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Markdown is translated as XML so that its structure survives the translation. Every line becomes an l element.
+// Markup which must not be translated (list markers, urls, code, ...) is wrapped in x elements, which are passed as
+// ignore_tags. Emphasis and links become inline elements which don't split sentences.
+const (
+	mdLineTag   = "l"
+	mdIgnoreTag = "x"
+)
+
+var mdInlineTags = []string{"b", "i", "a", "g"}
+
+var (
+	// mdPrefixRegex matches the block markup at the start of a line: indentation, headings, list items, quotes,
+	// definitions and footnote definitions
+	mdPrefixRegex = regexp.MustCompile(`^\s*(?:#{1,6} |[-*+] |\d+\. |> |:\s+|\[\^[^\]]+\]: )?`)
+	// mdRawLineRegex matches lines which are not translated at all: link reference definitions, table separators and
+	// rules
+	mdRawLineRegex = regexp.MustCompile(`^\s*(?:\[[^\]^]+\]: \S+|\|(?:\s*:?-+:?\s*\|)+|-{3,}|\*{3,})\s*$`)
+	// mdListItemRegex matches list items and definitions, lines indented below them are not code blocks
+	mdListItemRegex = regexp.MustCompile(`^\s*(?:[-*+]|\d+\.|:)\s`)
+	// mdInlineRegex matches inline markup. The submatches are: escaped character, code span, footnote reference,
+	// image (alt, url), inline link (text, url), reference link (text, ref), strong text and emphasized text.
+	mdInlineRegex = regexp.MustCompile(`(\\.)|` + "(`[^`]+`)" + `|(\[\^[^\]]+\])|` +
+		`(!\[([^\]]*)\]\(([^()\s]*(?:\([^()\s]*\)[^()\s]*)?)\))|` +
+		`(\[((?:[^\[\]\\]|\\.)*)\]\(([^()\s]*(?:\([^()\s]*\)[^()\s]*)?)\))|` +
+		`(\[((?:[^\[\]\\]|\\.)*)\]\[([^\]]+)\])|` +
+		`(\*\*(.+?)\*\*)|(_([^_\s](?:[^_]*[^_\s])?)_)`)
+	// mdCellSeparatorRegex matches the unescaped pipes separating table cells
+	mdCellSeparatorRegex = regexp.MustCompile(`(^|[^\\])\|`)
+)
+
+// MarkdownToXML converts markdown to the XML which is sent to the api when translating markdown, see XMLToMarkdown for
+// the reverse
+func MarkdownToXML(markdown string) string {
+	sb := strings.Builder{}
+	blank, lines := 0, 0
+	// state of code blocks, which are never translated: fenced, indented and whether the last block was a list or a
+	// definition
+	var fenced, indented, list bool
+	for _, line := range strings.Split(markdown, "\n") {
+		if line == "" {
+			blank++
+			continue
+		}
+
+		code := fenced
+		isFence := strings.HasPrefix(strings.TrimSpace(line), "```")
+		if isFence {
+			code, fenced = true, !fenced
+		} else if !fenced {
+			// an indented code block starts after a blank line, but not within a list or definition
+			isIndented := strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+			indented = isIndented && (indented || (blank > 0 || lines == 0) && !list)
+			code = indented
+			if !code {
+				list = mdListItemRegex.MatchString(line) || isIndented && list
+			}
+		}
+
+		// lines are separated by a single newline, blank lines by additional ones. Splitting large texts relies on
+		// blank lines between the blocks.
+		if lines > 0 {
+			blank++
+		}
+		sb.WriteString(strings.Repeat("\n", blank))
+		blank = 0
+		lines++
+
+		sb.WriteString("<" + mdLineTag + ">")
+		switch {
+		case code, mdRawLineRegex.MatchString(line):
+			sb.WriteString(ignored(line))
+		case strings.HasPrefix(strings.TrimSpace(line), "|"):
+			sb.WriteString(tableRowXML(line))
+		default:
+			prefix := mdPrefixRegex.FindString(line)
+			if prefix != "" {
+				sb.WriteString(ignored(prefix))
+			}
+			sb.WriteString(inlineXML(line[len(prefix):]))
+		}
+		sb.WriteString("</" + mdLineTag + ">")
+	}
+
+	if lines == 0 && blank > 0 {
+		// n newlines without any line split in to n+1 empty strings
+		blank--
+	}
+	sb.WriteString(strings.Repeat("\n", blank))
+
+	return sb.String()
+}
+
+func tableRowXML(line string) string {
+	sb := strings.Builder{}
+	last := 0
+	for _, m := range mdCellSeparatorRegex.FindAllStringSubmatchIndex(line, -1) {
+		pipe := m[1] - 1
+		sb.WriteString(inlineXML(line[last:pipe]))
+		sb.WriteString(ignored("|"))
+		last = m[1]
+	}
+	sb.WriteString(inlineXML(line[last:]))
+
+	return sb.String()
+}
+
+// inlineXML converts the inline markup of s
+func inlineXML(s string) string {
+	sb := strings.Builder{}
+	last := 0
+	for _, m := range mdInlineRegex.FindAllStringSubmatchIndex(s, -1) {
+		sb.WriteString(escapeXML(s[last:m[0]]))
+		last = m[1]
+
+		group := func(i int) string {
+			return s[m[2*i]:m[2*i+1]]
+		}
+		matched := func(i int) bool {
+			return m[2*i] != -1
+		}
+
+		switch {
+		case matched(1), matched(2), matched(3):
+			sb.WriteString(ignored(s[m[0]:m[1]]))
+		case matched(4):
+			sb.WriteString("<g s=\"" + escapeXML(group(6)) + "\">" + escapeXML(group(5)) + "</g>")
+		case matched(7):
+			sb.WriteString("<a h=\"" + escapeXML(group(9)) + "\">" + inlineXML(group(8)) + "</a>")
+		case matched(10):
+			sb.WriteString("<a r=\"" + escapeXML(group(12)) + "\">" + inlineXML(group(11)) + "</a>")
+		case matched(13):
+			sb.WriteString("<b>" + inlineXML(group(14)) + "</b>")
+		case matched(15):
+			sb.WriteString("<i>" + inlineXML(group(16)) + "</i>")
+		}
+	}
+	sb.WriteString(escapeXML(s[last:]))
+
+	return sb.String()
+}
+
+func ignored(s string) string {
+	return "<" + mdIgnoreTag + ">" + escapeXML(s) + "</" + mdIgnoreTag + ">"
+}
+
+func escapeXML(s string) string {
+	sb := strings.Builder{}
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// XMLToMarkdown converts XML created by MarkdownToXML (and translated by the api) back to markdown
+func XMLToMarkdown(x string) (string, error) {
+	dec := xml.NewDecoder(strings.NewReader(x))
+
+	sb := strings.Builder{}
+	lines := 0
+	// newlines between the lines
+	newlines := 0
+	var closing []string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid translation: %s", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			attr := func(name string) string {
+				for _, a := range t.Attr {
+					if a.Name.Local == name {
+						return a.Value
+					}
+				}
+				return ""
+			}
+
+			switch t.Name.Local {
+			case mdLineTag:
+				if lines > 0 && newlines == 0 {
+					newlines = 1
+				}
+				sb.WriteString(strings.Repeat("\n", newlines))
+				lines++
+				newlines = 0
+				closing = append(closing, "")
+			case "b":
+				sb.WriteString("**")
+				closing = append(closing, "**")
+			case "i":
+				sb.WriteString("_")
+				closing = append(closing, "_")
+			case "a":
+				sb.WriteString("[")
+				if ref := attr("r"); ref != "" {
+					closing = append(closing, "]["+ref+"]")
+				} else {
+					closing = append(closing, "]("+attr("h")+")")
+				}
+			case "g":
+				sb.WriteString("![")
+				closing = append(closing, "]("+attr("s")+")")
+			default:
+				closing = append(closing, "")
+			}
+		case xml.EndElement:
+			if len(closing) > 0 {
+				sb.WriteString(closing[len(closing)-1])
+				closing = closing[:len(closing)-1]
+			}
+		case xml.CharData:
+			if len(closing) == 0 {
+				// whitespace between lines
+				newlines += strings.Count(string(t), "\n")
+				continue
+			}
+			sb.WriteString(string(t))
+		}
+	}
+	sb.WriteString(strings.Repeat("\n", newlines))
+
+	return sb.String(), nil
+}
+
+// MarkdownCharacters returns the number of characters of markdown which TranslateMarkdown sends to be translated: the
+// text without markup, urls and code. It approximates the characters the translation is billed with.
+func MarkdownCharacters(markdown string) int {
+	dec := xml.NewDecoder(strings.NewReader(MarkdownToXML(markdown)))
+
+	count := 0
+	// depth of the elements and of the outermost ignored element, if any
+	depth, ignoredDepth := 0, 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			// MarkdownToXML only produces valid XML, the text ends with io.EOF
+			return count
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if t.Name.Local == mdIgnoreTag && ignoredDepth == 0 {
+				ignoredDepth = depth
+			}
+		case xml.EndElement:
+			if depth == ignoredDepth {
+				ignoredDepth = 0
+			}
+			depth--
+		case xml.CharData:
+			// whitespace between the lines is not part of the text
+			if depth > 0 && ignoredDepth == 0 {
+				count += utf8.RuneCount(t)
+			}
+		}
+	}
+}
+
+// TranslateMarkdown translates markdown using the tag handling of the api, so that its markup, urls and code are kept
+// intact
+func (c *client) TranslateMarkdown(markdown, targetLang, sourceLang string, opts ...TranslateOption) (string, error) {
+	opts = append([]TranslateOption{
+		WithTagHandling("xml"),
+		WithIgnoreTags(mdIgnoreTag),
+		WithNonSplittingTags(mdInlineTags...),
+		WithSplittingTags(mdLineTag),
+		withParam("outline_detection", "0"),
+		withParam("split_sentences", "nonewlines"),
+		withParam("preserve_formatting", "1"),
+	}, opts...)
+
+	translated, err := c.TranslateToString(MarkdownToXML(markdown), targetLang, sourceLang, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return XMLToMarkdown(translated)
+}
@@ -0,0 +1,142 @@
+package deepl
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownToXML(t *testing.T) {
+	tests := map[string]struct {
+		in, exp string
+	}{
+		"heading":   {in: "## History", exp: "<l><x>## </x>History</l>"},
+		"emphasis":  {in: "A **hearth** is _warm_", exp: "<l>A <b>hearth</b> is <i>warm</i></l>"},
+		"links":     {in: "[Smoke](https://en.wikipedia.org/wiki/Smoke_(thing)) and [fire][1]", exp: "<l><a h=\"https://en.wikipedia.org/wiki/Smoke_(thing)\">Smoke</a> and <a r=\"1\">fire</a></l>"},
+		"ignored":   {in: "`code` \\* [^1] a < b", exp: "<l><x>`code`</x> <x>\\*</x> <x>[^1]</x> a &lt; b</l>"},
+		"list":      {in: "- one\n  1. two", exp: "<l><x>- </x>one</l>\n<l><x>  1. </x>two</l>"},
+		"table":     {in: "| a \\| b | c |\n| --- | --- |", exp: "<l><x>|</x> a <x>\\|</x> b <x>|</x> c <x>|</x></l>\n<l><x>| --- | --- |</x></l>"},
+		"image":     {in: "![A hearth](images/hearth.jpg)", exp: "<l><g s=\"images/hearth.jpg\">A hearth</g></l>"},
+		"footnotes": {in: "A[^1]\n\n[^1]: Source\n[1]: https://example.com", exp: "<l>A<x>[^1]</x></l>\n\n<l><x>[^1]: </x>Source</l>\n<l><x>[1]: https://example.com</x></l>"},
+		"blank":     {in: "\nA\n\n\nB\n\n", exp: "\n<l>A</l>\n\n\n<l>B</l>\n\n"},
+		"fenced_code": {in: "A\n\n```go\nfor x in y:\n\n  foo bar\n```\nB",
+			exp: "<l>A</l>\n\n<l><x>```go</x></l>\n<l><x>for x in y:</x></l>\n\n<l><x>  foo bar</x></l>\n<l><x>```</x></l>\n<l>B</l>"},
+		"indented_code": {in: "    for x in y:\n\n        foo bar\nA",
+			exp: "<l><x>    for x in y:</x></l>\n\n<l><x>        foo bar</x></l>\n<l>A</l>"},
+		"nested_list": {in: "- one\n\n    - two\n    three",
+			exp: "<l><x>- </x>one</l>\n\n<l><x>    - </x>two</l>\n<l><x>    </x>three</l>"},
+		"definition": {in: "Hearth\n:   first\n\n    second paragraph\n\n    for x in y:",
+			exp: "<l>Hearth</l>\n<l><x>:   </x>first</l>\n\n<l><x>    </x>second paragraph</l>\n\n<l><x>    </x>for x in y:</l>"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if act := MarkdownToXML(tc.in); act != tc.exp {
+				t.Fatalf("Expected %q, got %q", tc.exp, act)
+			}
+
+			back, err := XMLToMarkdown(tc.exp)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+			if back != tc.in {
+				t.Fatalf("Expected %q to be converted back to %q, got %q", tc.exp, tc.in, back)
+			}
+		})
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	for _, article := range []string{"hearth", "ukraine", "warentrenner"} {
+		t.Run(article, func(t *testing.T) {
+			markdown, err := os.ReadFile("../test/data/articles/" + article + ".md")
+			if err != nil {
+				t.Fatalf("Failed to read article: %s", err.Error())
+			}
+
+			back, err := XMLToMarkdown(MarkdownToXML(string(markdown)))
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err.Error())
+			}
+			if back != string(markdown) {
+				t.Fatalf("Markdown changed by converting it to XML and back")
+			}
+		})
+	}
+}
+
+func TestMarkdownCharacters(t *testing.T) {
+	tests := map[string]struct {
+		in  string
+		exp int
+	}{
+		"plain":      {in: "Fire place", exp: 10},
+		"markup":     {in: "## Heat\n\n- **hot** [fire](https://example.com/fire)", exp: 12},
+		"code":       {in: "Run `w2d` now\n\n```\ncode\n```", exp: 8},
+		"unicode":    {in: "Україна", exp: 7},
+		"escaped":    {in: "a < b & c", exp: 9},
+		"empty":      {in: "\n\n", exp: 0},
+		"footnote":   {in: "Fire[^1]\n\n[^1]: Source", exp: 10},
+		"definition": {in: "Fire\n:   hot\n\n    very hot", exp: 15},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if act := MarkdownCharacters(tc.in); act != tc.exp {
+				t.Fatalf("Expected %d characters, got %d", tc.exp, act)
+			}
+		})
+	}
+}
+
+func TestXMLToMarkdownInvalid(t *testing.T) {
+	if _, err := XMLToMarkdown("<l>unclosed"); err == nil {
+		t.Fatalf("Expected an error for invalid XML")
+	}
+}
+
+func TestTranslateMarkdown(t *testing.T) {
+	c := client{
+		Endpoint: ProEndpoint,
+		AuthKey:  "abc",
+		client: NewTestClient(func(req *http.Request) *http.Response {
+			_ = req.ParseForm()
+			expParams := map[string]string{
+				"tag_handling":       "xml",
+				"ignore_tags":        "x",
+				"non_splitting_tags": "b,i,a,g",
+				"splitting_tags":     "l",
+			}
+			for k, v := range expParams {
+				if req.Form.Get(k) != v {
+					t.Fatalf("Parameter %s was expected to be %s, got %s", k, v, req.Form.Get(k))
+				}
+			}
+
+			// "translate" the text of the elements, but not the attributes and ignored tags
+			text := strings.ReplaceAll(req.Form.Get("text"), ">hearth<", ">Herd<")
+			body, _ := json.Marshal(map[string]interface{}{"translations": []map[string]string{{"text": text}}})
+
+			return &http.Response{
+				StatusCode: 200,
+				Body:       ioutil.NopCloser(bytes.NewBuffer(body)),
+				Header:     make(http.Header),
+			}
+		}),
+	}
+
+	in := "# hearth\n\n- A **hearth** in [hearth](https://en.wikipedia.org/wiki/hearth)\n\n`hearth`\n"
+	act, err := c.TranslateMarkdown(in, "de", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+
+	exp := "# Herd\n\n- A **Herd** in [Herd](https://en.wikipedia.org/wiki/hearth)\n\n`hearth`\n"
+	if act != exp {
+		t.Fatalf("Expected %q, got %q", exp, act)
+	}
+}
@@ -0,0 +1,152 @@
+package deepl
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// chunk is a part of a text which is translated on its own. Whitespace around the text is kept as the api does not
+// preserve it.
+type chunk struct {
+	leading, text, trailing string
+}
+
+// boundaries are the places a text is split at, from the most to the least preferable: sections, paragraphs, lines,
+// sentences and words. Sections start with a heading in markdown or in the XML of MarkdownToXML, which is captured so
+// that the text is split before it.
+var boundaries = []*regexp.Regexp{
+	regexp.MustCompile(`\n{2,}(#|<` + mdLineTag + `><` + mdIgnoreTag + `>#)`),
+	regexp.MustCompile(`\n{2,}`),
+	regexp.MustCompile(`\n`),
+	regexp.MustCompile(`[.!?]\s+`),
+	regexp.MustCompile(`\s+`),
+}
+
+// splitText splits text in to chunks whose url encoded size does not exceed maxSize. The concatenation of the chunks
+// (including their whitespace) equals text.
+func splitText(text string, maxSize int) []chunk {
+	var chunks []chunk
+	for _, part := range splitParts(text, maxSize, 0) {
+		trimmed := strings.TrimLeftFunc(part, unicode.IsSpace)
+		c := chunk{leading: part[:len(part)-len(trimmed)]}
+		c.text = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+		c.trailing = trimmed[len(c.text):]
+		chunks = append(chunks, c)
+	}
+
+	return chunks
+}
+
+// splitParts splits s at the boundaries starting with the given level, merging adjacent parts as long as they fit in to
+// maxSize
+func splitParts(s string, maxSize, level int) []string {
+	if encodedSize(s) <= maxSize {
+		return []string{s}
+	}
+
+	if level == len(boundaries) {
+		return splitRunes(s, maxSize)
+	}
+
+	var parts []string
+	for _, part := range splitAfter(s, boundaries[level]) {
+		if encodedSize(part) > maxSize {
+			parts = append(parts, splitParts(part, maxSize, level+1)...)
+		} else {
+			parts = append(parts, part)
+		}
+	}
+
+	return merge(parts, maxSize)
+}
+
+// splitAfter splits s after every match of boundary, or before its submatch if it has one
+func splitAfter(s string, boundary *regexp.Regexp) []string {
+	var parts []string
+	start := 0
+	for _, m := range boundary.FindAllStringSubmatchIndex(s, -1) {
+		end := m[1]
+		if len(m) > 2 && m[2] != -1 {
+			end = m[2]
+		}
+		if end > start {
+			parts = append(parts, s[start:end])
+			start = end
+		}
+	}
+
+	if start < len(s) {
+		parts = append(parts, s[start:])
+	}
+
+	return parts
+}
+
+// splitRunes splits s in to parts of at most maxSize without splitting runes, used if a text has no other boundaries
+func splitRunes(s string, maxSize int) []string {
+	var parts []string
+	start, size := 0, 0
+	for i, r := range s {
+		runeSize := encodedSize(string(r))
+		if size+runeSize > maxSize && i > start {
+			parts = append(parts, s[start:i])
+			start, size = i, 0
+		}
+		size += runeSize
+	}
+
+	return append(parts, s[start:])
+}
+
+// merge joins adjacent parts as long as the result fits in to maxSize
+func merge(parts []string, maxSize int) []string {
+	var merged []string
+	current := ""
+	for _, part := range parts {
+		if current != "" && encodedSize(current+part) > maxSize {
+			merged = append(merged, current)
+			current = ""
+		}
+		current += part
+	}
+
+	if current != "" {
+		merged = append(merged, current)
+	}
+
+	return merged
+}
+
+// batchChunks groups the indices of the non-empty chunks in to batches which fit in to a single request
+func batchChunks(chunks []chunk, maxSize int) [][]int {
+	var batches [][]int
+	var batch []int
+	size := 0
+	for i, c := range chunks {
+		if c.text == "" {
+			continue
+		}
+
+		textSize := encodedSize(c.text)
+		if len(batch) > 0 && (size+len("&text=")+textSize > maxSize || len(batch) == MaxTexts) {
+			batches = append(batches, batch)
+			batch, size = nil, 0
+		}
+
+		batch = append(batch, i)
+		size += len("&text=") + textSize
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// encodedSize returns the size of s in a form encoded request body
+func encodedSize(s string) int {
+	return len(url.QueryEscape(s))
+}
@@ -0,0 +1,65 @@
+package deepl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitText(t *testing.T) {
+	tests := map[string]struct {
+		text    string
+		maxSize int
+		exp     []string
+	}{
+		"fits":       {text: "Hallo Welt!", maxSize: 100, exp: []string{"Hallo Welt!"}},
+		"paragraphs": {text: "aaaa\n\nbbbb\n\ncccc", maxSize: 13, exp: []string{"aaaa", "bbbb", "cccc"}},
+		"merged":     {text: "aa\n\nbb\n\ncccc", maxSize: 16, exp: []string{"aa\n\nbb", "cccc"}},
+		"sections":   {text: "# A\n\naa\n\n# B\n\nbb", maxSize: 28, exp: []string{"# A\n\naa", "# B\n\nbb"}},
+		"lines":      {text: "aaaa\nbbbb\ncccc", maxSize: 10, exp: []string{"aaaa", "bbbb", "cccc"}},
+		"sentences":  {text: "Aaa bb. Ccc dd. Eee ff.", maxSize: 20, exp: []string{"Aaa bb. Ccc dd.", "Eee ff."}},
+		"runes":      {text: "ääää", maxSize: 12, exp: []string{"ää", "ää"}},
+		"xml_sections": {text: "<l><x># </x>A</l>\n\n<l>a</l>\n\n<l><x># </x>B</l>\n\n<l>bbbbbbbbbbbbbbbbbbbb</l>", maxSize: 120,
+			exp: []string{"<l><x># </x>A</l>\n\n<l>a</l>", "<l><x># </x>B</l>\n\n<l>bbbbbbbbbbbbbbbbbbbb</l>"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			chunks := splitText(tc.text, tc.maxSize)
+
+			var texts []string
+			sb := strings.Builder{}
+			for _, c := range chunks {
+				if size := encodedSize(c.text); size > tc.maxSize {
+					t.Fatalf("Chunk %q exceeds the maximum size %d", c.text, tc.maxSize)
+				}
+				texts = append(texts, c.text)
+				sb.WriteString(c.leading + c.text + c.trailing)
+			}
+
+			if sb.String() != tc.text {
+				t.Fatalf("Chunks should add up to the text\nExpected: %q\nActual: %q", tc.text, sb.String())
+			}
+			if strings.Join(texts, "|") != strings.Join(tc.exp, "|") {
+				t.Fatalf("Expected chunks %q, got %q", tc.exp, texts)
+			}
+		})
+	}
+}
+
+func TestBatchChunks(t *testing.T) {
+	chunks := make([]chunk, MaxTexts+5)
+	for i := range chunks {
+		chunks[i] = chunk{text: "a"}
+	}
+	chunks[3].text = ""
+
+	batches := batchChunks(chunks, 1000)
+	if len(batches) != 2 || len(batches[0]) != MaxTexts || len(batches[1]) != 4 {
+		t.Fatalf("Expected batches of %d and 4 texts, got %v", MaxTexts, batches)
+	}
+
+	batches = batchChunks(chunks[:4], len("&text=a&text=a"))
+	if len(batches) != 2 || len(batches[0]) != 2 || batches[1][0] != 2 {
+		t.Fatalf("Expected two batches of size 2 without the empty chunk, got %v", batches)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/IljaN/w2d/deepl"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type glossaryArgs struct {
+	Create *glossaryCreateArgs `arg:"subcommand:create" help:"creates a glossary from a TSV or CSV file with one term and its translation per line"`
+	List   *glossaryListArgs   `arg:"subcommand:list" help:"lists all glossaries"`
+	Show   *glossaryIDArgs     `arg:"subcommand:show" help:"shows a glossary and its entries"`
+	Delete *glossaryIDArgs     `arg:"subcommand:delete" help:"deletes a glossary"`
+	Pairs  *glossaryListArgs   `arg:"subcommand:pairs" help:"lists the language pairs glossaries can be created for"`
+	authKey
+}
+
+type glossaryCreateArgs struct {
+	Name       string `arg:"positional,required" help:"name of the glossary"`
+	SourceLang string `arg:"positional,required" help:"language of the terms"`
+	TargetLang string `arg:"positional,required" help:"language of the translations"`
+	File       string `arg:"positional" default:"-" help:"TSV or CSV file with the entries or '-' for STDIN"`
+	Format     string `arg:"--format" help:"format of the entries (tsv or csv), defaults to the file extension or tsv"`
+}
+
+type glossaryListArgs struct{}
+
+type glossaryIDArgs struct {
+	ID string `arg:"positional,required" help:"id of the glossary"`
+}
+
+// newGlossaryCmd returns cmd-function which manages the glossaries of a DeepL account
+func newGlossaryCmd(deepl deepl.Client) func(args *glossaryArgs) (string, error) {
+	return func(args *glossaryArgs) (string, error) {
+		switch {
+		case args.Create != nil:
+			return createGlossary(deepl, args.Create)
+		case args.List != nil:
+			glossaries, err := deepl.Glossaries()
+			if err != nil {
+				return "", err
+			}
+
+			sb := strings.Builder{}
+			for _, g := range glossaries {
+				sb.WriteString(formatGlossary(g) + "\n")
+			}
+			return sb.String(), nil
+		case args.Show != nil:
+			g, err := deepl.Glossary(args.Show.ID)
+			if err != nil {
+				return "", err
+			}
+
+			entries, err := deepl.GlossaryEntries(args.Show.ID)
+			if err != nil {
+				return "", err
+			}
+
+			sb := strings.Builder{}
+			sb.WriteString(formatGlossary(g) + "\n\n")
+			for _, e := range entries {
+				sb.WriteString(e.Source + "\t" + e.Target + "\n")
+			}
+			return sb.String(), nil
+		case args.Delete != nil:
+			if err := deepl.DeleteGlossary(args.Delete.ID); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Deleted glossary %s\n", args.Delete.ID), nil
+		case args.Pairs != nil:
+			pairs, err := deepl.GlossaryLanguagePairs()
+			if err != nil {
+				return "", err
+			}
+
+			lines := make([]string, 0, len(pairs))
+			for _, p := range pairs {
+				lines = append(lines, p.SourceLang+" -> "+p.TargetLang+"\n")
+			}
+			sort.Strings(lines)
+			return strings.Join(lines, ""), nil
+		}
+
+		return "", errors.New("command required (create, list, show, delete or pairs)")
+	}
+}
+
+func createGlossary(client deepl.Client, args *glossaryCreateArgs) (string, error) {
+	format := args.Format
+	if format == "" {
+		format = "tsv"
+		if strings.EqualFold(filepath.Ext(args.File), ".csv") {
+			format = "csv"
+		}
+	}
+
+	f, err := openList(args.File)
+	if err != nil {
+		return "", err
+	}
+	entries, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return "", err
+	}
+
+	// validate locally for a helpful error message
+	if _, err := deepl.ParseGlossaryEntries(string(entries), format); err != nil {
+		return "", err
+	}
+
+	g, err := client.CreateGlossary(args.Name, args.SourceLang, args.TargetLang, string(entries), format)
+	if err != nil {
+		return "", err
+	}
+
+	return "Created glossary " + formatGlossary(g) + "\n", nil
+}
+
+func formatGlossary(g deepl.Glossary) string {
+	return fmt.Sprintf("%s - %s (%s -> %s, %d entries)", g.ID, g.Name, g.SourceLang, g.TargetLang, g.EntryCount)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"github.com/IljaN/w2d/deepl"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeDeepl is a deepl.Client keeping its glossaries in memory. Methods which are not overridden panic.
+type fakeDeepl struct {
+	deepl.Client
+	glossaries map[string]deepl.Glossary
+	entries    map[string]string
+	usage      deepl.Usage
+	usageErr   error
+}
+
+func (f *fakeDeepl) CreateGlossary(name, sourceLang, targetLang, entries, format string) (deepl.Glossary, error) {
+	parsed, err := deepl.ParseGlossaryEntries(entries, format)
+	if err != nil {
+		return deepl.Glossary{}, err
+	}
+
+	g := deepl.Glossary{ID: "g1", Name: name, Ready: true, SourceLang: sourceLang, TargetLang: targetLang, EntryCount: len(parsed)}
+	f.glossaries[g.ID] = g
+	f.entries[g.ID] = ""
+	for _, e := range parsed {
+		f.entries[g.ID] += e.Source + "\t" + e.Target + "\n"
+	}
+
+	return g, nil
+}
+
+func (f *fakeDeepl) Glossary(id string) (deepl.Glossary, error) {
+	g, ok := f.glossaries[id]
+	if !ok {
+		return deepl.Glossary{}, errors.New("Glossary not found")
+	}
+
+	return g, nil
+}
+
+func (f *fakeDeepl) GlossaryEntries(id string) ([]deepl.GlossaryEntry, error) {
+	if _, ok := f.glossaries[id]; !ok {
+		return nil, errors.New("Glossary not found")
+	}
+
+	return deepl.ParseGlossaryEntries(f.entries[id], deepl.GlossaryTSV)
+}
+
+func (f *fakeDeepl) Usage() (deepl.Usage, error) {
+	return f.usage, f.usageErr
+}
+
+func TestGlossaryCmd(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	tests := map[string]struct {
+		file, format string
+		created      string
+		shown        string
+		err          string
+	}{
+		"tsv": {
+			file:    writeFile("fire.tsv", "hearth\tHerd\n\"fire\"\tFeuer\n"),
+			created: "Created glossary g1 - Fire (en -> de, 2 entries)\n",
+			shown:   "g1 - Fire (en -> de, 2 entries)\n\nhearth\tHerd\n\"fire\"\tFeuer\n",
+		},
+		"csv_extension": {
+			file:    writeFile("fire.csv", "hearth,Herd\n\"fire, open\",offenes Feuer\n"),
+			created: "Created glossary g1 - Fire (en -> de, 2 entries)\n",
+			shown:   "g1 - Fire (en -> de, 2 entries)\n\nhearth\tHerd\nfire, open\toffenes Feuer\n",
+		},
+		"csv_flag": {
+			file:    writeFile("fire.txt", "hearth,Herd\n"),
+			format:  "csv",
+			created: "Created glossary g1 - Fire (en -> de, 1 entries)\n",
+			shown:   "g1 - Fire (en -> de, 1 entries)\n\nhearth\tHerd\n",
+		},
+		"invalid": {
+			file: writeFile("invalid.tsv", "hearth\tHerd\nfire\n"),
+			err:  "invalid glossary entry in line 2",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			glossary := newGlossaryCmd(&fakeDeepl{glossaries: map[string]deepl.Glossary{}, entries: map[string]string{}})
+
+			out, err := glossary(&glossaryArgs{Create: &glossaryCreateArgs{Name: "Fire", SourceLang: "en", TargetLang: "de", File: tc.file, Format: tc.format}})
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.err)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.created, out)
+
+			out, err = glossary(&glossaryArgs{Show: &glossaryIDArgs{ID: "g1"}})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.shown, out)
+
+			_, err = glossary(&glossaryArgs{Show: &glossaryIDArgs{ID: "missing"}})
+			assert.Error(t, err)
+		})
+	}
+}
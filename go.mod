@@ -6,7 +6,9 @@ require (
 	github.com/JohannesKaufmann/html-to-markdown v1.3.3
 	github.com/PuerkitoBio/goquery v1.8.0
 	github.com/alexflint/go-arg v1.4.3
+	github.com/klauspost/compress v1.16.7
 	github.com/stretchr/testify v1.7.0
+	github.com/ulikunitz/xz v0.5.12
 	golang.org/x/net v0.17.0
 )
 
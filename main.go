@@ -8,8 +8,8 @@ import (
 	"github.com/alexflint/go-arg"
 	"io"
 	"net/http"
-	"net/url"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 )
@@ -18,6 +18,12 @@ type rootArgs struct {
 	Translate     *translateArgs     `arg:"subcommand:translate" help:"translates a wikipedia article"`
 	Markdown      *markdownArgs      `arg:"subcommand:markdown" help:"converts wikipedia article html to markdown"`
 	ListLanguages *listLanguagesArgs `arg:"subcommand:list-languages" help:"retrieve a list of supported languages"`
+	LangLinks     *langLinksArgs     `arg:"subcommand:langlinks" help:"lists the languages an article is available in"`
+	Batch         *batchArgs         `arg:"subcommand:batch" help:"converts (and translates) a list of articles in to files"`
+	Category      *categoryArgs      `arg:"subcommand:category" help:"converts (and translates) the articles of a category in to files"`
+	Crawl         *crawlArgs         `arg:"subcommand:crawl" help:"converts (and translates) an article and the articles it links to in to linked files"`
+	Glossary      *glossaryArgs      `arg:"subcommand:glossary" help:"manages DeepL glossaries"`
+	Usage         *usageArgs         `arg:"subcommand:usage" help:"shows the character usage and limit of the DeepL account"`
 }
 
 func (rootArgs) Description() string {
@@ -34,47 +40,299 @@ type authKey struct {
 	DeeplAuthKey string `arg:"required,-k,--,env:W2D_DEEPL_AUTH_KEY"`
 }
 
+// parserArgs are shared by all commands which convert articles
+type parserArgs struct {
+	Infobox   wikipedia.InfoboxMode `arg:"--infobox" default:"omit" help:"render the infobox (omit, summary or front-matter)"`
+	Links     wikipedia.LinkMode    `arg:"--links" default:"strip" help:"render links (strip, inline or reference)"`
+	Footnotes bool                  `arg:"--footnotes" help:"render citations as footnotes"`
+	Images    bool                  `arg:"--images" help:"render thumbnails with their caption"`
+	ImageDir  string                `arg:"--image-dir" help:"download images in to this directory and link them relatively, implies --images"`
+
+	Sections         []string `arg:"--section,separate" help:"only include this section (index of a top-level section or heading text), repeatable"`
+	ExcludedSections []string `arg:"--exclude-section,separate" help:"exclude this section (index or heading text, 0 for the lead), repeatable"`
+	LeadOnly         bool     `arg:"--lead-only" help:"only include the lead"`
+
+	TOC         bool `arg:"--toc" help:"insert a table of contents after the title"`
+	FrontMatter bool `arg:"--front-matter" help:"prepend YAML front matter with the metadata of the article"`
+}
+
+func (a parserArgs) options() []wikipedia.Option {
+	opts := []wikipedia.Option{
+		wikipedia.WithInfobox(a.Infobox),
+		wikipedia.WithLinkMode(a.Links),
+		wikipedia.WithFootnotes(a.Footnotes),
+		wikipedia.WithImages(a.Images || a.ImageDir != ""),
+		wikipedia.WithSections(a.Sections...),
+		wikipedia.WithExcludedSections(a.ExcludedSections...),
+		wikipedia.WithFrontMatter(a.FrontMatter),
+	}
+
+	if a.LeadOnly {
+		opts = append(opts, wikipedia.WithLeadOnly())
+	}
+
+	return opts
+}
+
+// translationArgs are shared by all commands which translate articles
+type translationArgs struct {
+	Formality deepl.Formality `arg:"--formality" help:"formality of the translation (default, more, less, prefer_more or prefer_less), more and less are only supported by some target languages"`
+	Glossary  string          `arg:"--glossary" help:"id of the DeepL glossary to translate with, requires a source language (-s)"`
+
+	CheckQuota bool `arg:"--check-quota" help:"refuse to translate articles which exceed the remaining character quota of the DeepL account"`
+}
+
+func (a translationArgs) translateOptions() []deepl.TranslateOption {
+	return []deepl.TranslateOption{deepl.WithFormality(a.Formality), deepl.WithGlossary(a.Glossary)}
+}
+
+// postProcess applies the steps which operate on the final (translated) markdown. file is the path the markdown is
+// written to or empty if it is printed.
+func (a parserArgs) postProcess(markdown, file string) (string, error) {
+	if a.TOC {
+		markdown = wikipedia.InsertTOC(markdown)
+	}
+
+	if a.ImageDir == "" {
+		return markdown, nil
+	}
+
+	base := ""
+	if file != "" {
+		base = filepath.Dir(file)
+	}
+
+	return wikipedia.DownloadImages(markdown, a.ImageDir, base, http.DefaultClient)
+}
+
+// sourceArgs are shared by all commands which read articles
+type sourceArgs struct {
+	Wiki     string `arg:"--wiki,env:W2D_WIKI" default:"en" help:"language of the wikipedia to look up titles without language prefix"`
+	Revision int    `arg:"--revision" help:"id of the revision of the article to convert, defaults to the latest"`
+	Dump     string `arg:"--dump,env:W2D_DUMP" help:"read the article by its title from this XML dump (pages-articles.xml or .xml.bz2) instead of wikipedia"`
+	ZIM      string `arg:"--zim,env:W2D_ZIM" help:"read the article by its title from this Kiwix ZIM archive instead of wikipedia"`
+}
+
+// openArticle returns the article src, which is either a url, a title (optionally prefixed with a language like
+// en:Hearth) or '-' to read the html of the article from STDIN. If a dump or ZIM archive is given, src is the title of
+// the article in the archive. Urls which don't name an article of a wiki are fetched as they are.
+func (a sourceArgs) openArticle(src string) (*wikipedia.Page, error) {
+	if src == "-" {
+		if !stdInAttached() {
+			return nil, errors.New("stdin redirection required if '-' is given")
+		}
+
+		html, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+
+		return &wikipedia.Page{HTML: string(html)}, nil
+	}
+
+	if a.Dump != "" || a.ZIM != "" {
+		if a.Revision != 0 {
+			return nil, errors.New("--revision can't be used with --dump or --zim, archives only contain a single revision")
+		}
+		if a.Dump != "" && a.ZIM != "" {
+			return nil, errors.New("--dump and --zim can't be used together")
+		}
+	}
+
+	if a.Dump != "" {
+		return wikipedia.NewDump(a.Dump).Fetch(src)
+	}
+
+	if a.ZIM != "" {
+		z, err := wikipedia.OpenZIM(a.ZIM)
+		if err != nil {
+			return nil, err
+		}
+		defer z.Close()
+
+		return z.Fetch(src)
+	}
+
+	page, err := wikipedia.NewClient(http.DefaultClient).Fetch(src, a.Wiki, a.Revision)
+	if errors.Is(err, wikipedia.ErrNoArticleURL) && a.Revision == 0 {
+		// pages the api can't be asked for are converted as they are served
+		return fetchPage(src)
+	}
+
+	return page, err
+}
+
+// fetchPage downloads the html of the page at the url src
+func fetchPage(src string) (*wikipedia.Page, error) {
+	resp, err := http.Get(src)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", src, resp.Status)
+	}
+
+	html, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wikipedia.Page{URL: src, HTML: string(html)}, nil
+}
+
 type translateArgs struct {
 	TargetLang string `arg:"positional,required" help:"target language for translation"`
-	Article    string `arg:"positional,required" help:"full url to the article or '-' for STDIN"`
+	Article    string `arg:"positional,required" help:"url or title (e.g. en:Hearth) of the article or '-' for STDIN"`
 	SourceLang string `arg:"-s,--" default:"" help:"source language, leave empty for autodetect"`
 
+	PreferNative bool `arg:"--prefer-native" help:"convert the existing version of the article in the target language instead of translating, if there is one"`
+
+	sourceArgs
+	parserArgs
+	translationArgs
 	authKey
 }
 
 // newTranslateCmd returns cmd-function which fetches an article from wikipedia, parses to markdown and translates it using DeepL
-func newTranslateCmd(parser *wikipedia.ArticleParser, deepl deepl.Client) func(articleHTML io.ReadCloser, tgtLang, srcLang string) (string, error) {
-	return func(articleHTML io.ReadCloser, tgtLang, srcLang string) (string, error) {
-		markdown, err := parser.Parse(articleHTML)
+func newTranslateCmd(parser *wikipedia.ArticleParser, deepl deepl.Client, translation translationArgs) func(page *wikipedia.Page, tgtLang, srcLang string) (string, error) {
+	return func(page *wikipedia.Page, tgtLang, srcLang string) (string, error) {
+		article, err := parser.ParsePage(page)
 		if err != nil {
 			return "", fmt.Errorf("failed to parse: %s", err)
 		}
 
-		translated, err := deepl.TranslateToString(markdown, tgtLang, srcLang)
+		// front matter is meant to be machine-readable, only the body is translated
+		body := parser.RenderBody(article)
+		if translation.CheckQuota {
+			if err := checkQuota(deepl, body); err != nil {
+				return "", err
+			}
+		}
+
+		translated, err := deepl.TranslateMarkdown(body, tgtLang, srcLang, translation.translateOptions()...)
 		if err != nil {
 			return "", fmt.Errorf("failed to translate article: %s", err)
 		}
 
-		return translated, nil
+		return parser.RenderFrontMatter(article) + translated, nil
+	}
+}
+
+// checkQuota returns an error if translating the markdown would exceed the remaining character quota. Only the text
+// sent for translation is counted (see deepl.MarkdownCharacters), which may differ slightly from the billed characters.
+func checkQuota(client deepl.Client, markdown string) error {
+	usage, err := client.Usage()
+	if err != nil {
+		return fmt.Errorf("failed to check quota: %s", err)
+	}
+
+	if length := int64(deepl.MarkdownCharacters(markdown)); length > usage.Remaining() {
+		return fmt.Errorf("article has %d characters to translate, but only %d of %d characters are left in the quota",
+			length, usage.Remaining(), usage.CharacterLimit)
+	}
+
+	return nil
+}
+
+// newUsageCmd returns cmd-function which shows the character usage of the DeepL account
+func newUsageCmd(deepl deepl.Client) func() (string, error) {
+	return func() (string, error) {
+		usage, err := deepl.Usage()
+		if err != nil {
+			return "", err
+		}
+
+		percent := 0.0
+		if usage.CharacterLimit > 0 {
+			percent = float64(usage.CharacterCount) / float64(usage.CharacterLimit) * 100
+		}
+
+		return fmt.Sprintf("Characters: %d of %d (%.1f%%), %d left\n", usage.CharacterCount, usage.CharacterLimit,
+			percent, usage.Remaining()), nil
+	}
+}
+
+// newNativeCmd returns cmd-function which looks up the version of an article in the wikipedia of tgtLang and converts it
+// to markdown. found is false if there is no such version.
+func newNativeCmd(parser *wikipedia.ArticleParser, wiki wikipedia.Client) func(page *wikipedia.Page, tgtLang string) (markdown string, found bool, err error) {
+	return func(page *wikipedia.Page, tgtLang string) (string, bool, error) {
+		// the url of articles read from STDIN is only known from their html
+		ref := page.URL
+		if ref == "" {
+			article, err := parser.ParsePage(page)
+			if err != nil {
+				return "", false, fmt.Errorf("failed to parse: %s", err)
+			}
+			ref = article.Metadata.URL
+		}
+
+		if ref == "" {
+			return "", false, errors.New("the url of the article is required to look up its other languages")
+		}
+
+		links, err := wiki.LangLinks(ref, "")
+		if err != nil {
+			return "", false, fmt.Errorf("failed to get languages of article: %s", err)
+		}
+
+		lang := wikipedia.WikiLanguage(tgtLang)
+		for _, l := range links {
+			if l.Lang != lang {
+				continue
+			}
+
+			native, err := wiki.Fetch(l.URL, "", 0)
+			if err != nil {
+				return "", false, err
+			}
+
+			markdown, err := newMarkdownCmd(parser)(native)
+			return markdown, true, err
+		}
+
+		return "", false, nil
+	}
+}
+
+// newPreferNativeCmd returns cmd-function which converts the version of an article in the wikipedia of tgtLang if there
+// is one and translates the article with translate otherwise
+func newPreferNativeCmd(parser *wikipedia.ArticleParser, wiki wikipedia.Client, translate func(page *wikipedia.Page, tgtLang, srcLang string) (string, error)) func(page *wikipedia.Page, tgtLang, srcLang string) (string, error) {
+	native := newNativeCmd(parser, wiki)
+	return func(page *wikipedia.Page, tgtLang, srcLang string) (string, error) {
+		markdown, found, err := native(page, tgtLang)
+		if err != nil || found {
+			return markdown, err
+		}
+
+		return translate(page, tgtLang, srcLang)
 	}
 }
 
 type markdownArgs struct {
-	Article string `arg:"positional" default:"" help:"full url to the article or '-' for STDIN"`
+	Article string `arg:"positional" default:"" help:"url or title (e.g. en:Hearth) of the article or '-' for STDIN"`
+
+	sourceArgs
+	parserArgs
 }
 
 // newMarkdownCmd returns cmd-function witch fetches an article from wikipedia and converts it to markdown
-func newMarkdownCmd(parser *wikipedia.ArticleParser) func(articleHTML io.ReadCloser) (string, error) {
-	return func(articleHTML io.ReadCloser) (string, error) {
-		markdown, err := parser.Parse(articleHTML)
+func newMarkdownCmd(parser *wikipedia.ArticleParser) func(page *wikipedia.Page) (string, error) {
+	return func(page *wikipedia.Page) (string, error) {
+		article, err := parser.ParsePage(page)
 		if err != nil {
 			return "", fmt.Errorf("failed to parse: %s", err)
 		}
 
-		return markdown, nil
+		return parser.Render(article), nil
 	}
 }
 
+type usageArgs struct {
+	authKey
+}
+
 type listLanguagesArgs struct {
 	Type string `arg:"-t,--" default:"source" help:"Which type of languages to return (source or target)"`
 	authKey
@@ -108,6 +366,32 @@ func newListLanguagesCmd(deepl deepl.Client) func(langType string) (string, erro
 	}
 }
 
+type langLinksArgs struct {
+	Article string `arg:"positional,required" help:"url or title (e.g. en:Hearth) of the article"`
+	Wiki    string `arg:"--wiki,env:W2D_WIKI" default:"en" help:"language of the wikipedia to look up titles without language prefix"`
+}
+
+// newLangLinksCmd returns cmd-function which lists the versions of an article in other languages
+func newLangLinksCmd(wiki wikipedia.Client) func(ref, defaultLang string) (string, error) {
+	return func(ref, defaultLang string) (string, error) {
+		links, err := wiki.LangLinks(ref, defaultLang)
+		if err != nil {
+			return "", err
+		}
+
+		sort.Slice(links, func(i, j int) bool {
+			return links[i].Lang < links[j].Lang
+		})
+
+		res := strings.Builder{}
+		for _, l := range links {
+			res.WriteString(fmt.Sprintf("%s - %s: %s (%s)\n", l.Lang, l.Name, l.Title, l.URL))
+		}
+
+		return res.String(), nil
+	}
+}
+
 // w2d - translates wikipedia articles using DeepL api and renders them to markdown.
 func main() {
 	var out, cmdName string
@@ -117,61 +401,138 @@ func main() {
 
 	switch {
 	case args.Translate != nil:
-		var articleHTML io.ReadCloser
+		var page *wikipedia.Page
 		cmdName = "translate"
-		translate := newTranslateCmd(wikipedia.NewArticleParser(), deepl.NewClient(args.Translate.DeeplAuthKey))
-		articleHTML, err = openArticle(args.Translate.Article)
+		opts := append(args.Translate.options(), wikipedia.WithTranslation(args.Translate.SourceLang, args.Translate.TargetLang, "deepl"))
+		translate := newTranslateCmd(wikipedia.NewArticleParser(opts...), deepl.NewClient(args.Translate.DeeplAuthKey), args.Translate.translationArgs)
+		page, err = args.Translate.openArticle(args.Translate.Article)
 		if err != nil {
 			break
 		}
 
-		out, err = translate(articleHTML, args.Translate.TargetLang, args.Translate.SourceLang)
+		if args.Translate.PreferNative {
+			translate = newPreferNativeCmd(wikipedia.NewArticleParser(args.Translate.options()...), wikipedia.NewClient(http.DefaultClient), translate)
+		}
+
+		out, err = translate(page, args.Translate.TargetLang, args.Translate.SourceLang)
+		if err != nil {
+			break
+		}
+
+		out, err = args.Translate.postProcess(out, "")
 	case args.Markdown != nil:
-		var articleHTML io.ReadCloser
+		var page *wikipedia.Page
 		cmdName = "markdown"
-		markdown := newMarkdownCmd(wikipedia.NewArticleParser())
-		articleHTML, err = openArticle(args.Markdown.Article)
+		markdown := newMarkdownCmd(wikipedia.NewArticleParser(args.Markdown.options()...))
+		page, err = args.Markdown.openArticle(args.Markdown.Article)
 		if err != nil {
 			break
 		}
 
-		out, err = markdown(articleHTML)
+		out, err = markdown(page)
+		if err != nil {
+			break
+		}
+
+		out, err = args.Markdown.postProcess(out, "")
 	case args.ListLanguages != nil:
 		cmdName = "list-languages"
 		listLanguages := newListLanguagesCmd(deepl.NewClient(args.ListLanguages.DeeplAuthKey))
 		out, err = listLanguages(args.ListLanguages.Type)
-	}
+	case args.LangLinks != nil:
+		cmdName = "langlinks"
+		langLinks := newLangLinksCmd(wikipedia.NewClient(http.DefaultClient))
+		out, err = langLinks(args.LangLinks.Article, args.LangLinks.Wiki)
+	case args.Batch != nil:
+		var convert func(page *wikipedia.Page, file string) (string, error)
+		var list io.ReadCloser
+		var refs []string
+		cmdName = "batch"
+		convert, err = args.Batch.converter()
+		if err != nil {
+			break
+		}
 
-	if err != nil {
-		_ = p.FailSubcommand(err.Error(), cmdName)
-		os.Exit(1)
-	}
+		list, err = openList(args.Batch.List)
+		if err != nil {
+			break
+		}
 
-	fmt.Print(out)
-	os.Exit(0)
-}
+		refs, err = readArticleList(list)
+		list.Close()
+		if err != nil {
+			break
+		}
 
-// openArticle returns a reader for an article at srcUrl. If STDIN is attached srcUrl is ignored.
-func openArticle(src string) (io.ReadCloser, error) {
-	if src == "-" {
-		if stdInAttached() {
-			return os.Stdin, nil
+		batch := newBatchCmd(wikipedia.NewClient(http.DefaultClient), convert)
+		out, err = batch(refs, args.Batch.Wiki, args.Batch.Out, args.Batch.Name, args.Batch.TargetLang)
+	case args.Category != nil:
+		var convert func(page *wikipedia.Page, file string) (string, error)
+		var members []wikipedia.CategoryMember
+		cmdName = "category"
+		if err = args.Category.validate(); err != nil {
+			break
+		}
+
+		convert, err = args.Category.converter()
+		if err != nil {
+			break
+		}
+
+		wiki := wikipedia.NewClient(http.DefaultClient)
+		members, err = wiki.CategoryMembers(args.Category.Category, args.Category.Wiki, args.Category.Depth, args.Category.Limit)
+		if err != nil {
+			break
 		}
 
-		return nil, errors.New("stdin redirection required if '-' is given")
+		refs := make([]string, 0, len(members))
+		for _, m := range members {
+			refs = append(refs, m.URL)
+		}
+
+		batch := newBatchCmd(wiki, convert)
+		out, err = batch(refs, args.Category.Wiki, args.Category.Out, args.Category.Name, args.Category.TargetLang)
+	case args.Crawl != nil:
+		cmdName = "crawl"
+		a := args.Crawl
+		if err = a.validate(); err != nil {
+			break
+		}
+
+		converter := func(extra ...wikipedia.Option) (func(page *wikipedia.Page, file string) (string, error), error) {
+			// links are the point of crawling, so they are kept even if not asked for
+			if a.Links == wikipedia.LinkStrip {
+				extra = append(extra, wikipedia.WithLinkMode(wikipedia.LinkInline))
+			}
+
+			return a.converter(extra...)
+		}
+
+		crawl := newCrawlCmd(wikipedia.NewClient(http.DefaultClient), wikipedia.NewArticleParser(a.options()...), converter)
+		out, err = crawl(a.Article, a.Wiki, a.Hops, a.Limit, a.Out, a.Name, a.TargetLang)
+	case args.Glossary != nil:
+		cmdName = "glossary"
+		glossary := newGlossaryCmd(deepl.NewClient(args.Glossary.DeeplAuthKey))
+		out, err = glossary(args.Glossary)
+	case args.Usage != nil:
+		cmdName = "usage"
+		usage := newUsageCmd(deepl.NewClient(args.Usage.DeeplAuthKey))
+		out, err = usage()
 	}
 
-	u, err := url.ParseRequestURI(src)
-	if err != nil {
-		return nil, err
+	if errors.Is(err, errBatchFailed) {
+		// the failures are listed in the summary
+		fmt.Print(out)
+		os.Exit(1)
 	}
 
-	resp, err := http.Get(u.String())
 	if err != nil {
-		return nil, err
+		_ = p.FailSubcommand(err.Error(), cmdName)
+		os.Exit(1)
 	}
 
-	return resp.Body, nil
+	fmt.Print(out)
+	os.Exit(0)
 }
 
 func stdInAttached() bool {
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	"github.com/IljaN/w2d/deepl"
+	"github.com/IljaN/w2d/wikipedia"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenArticle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/about.html" {
+			http.NotFound(w, r)
+			return
+		}
+
+		_, _ = w.Write([]byte("<p>about</p>"))
+	}))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		src  string
+		args sourceArgs
+		exp  string
+		err  string
+	}{
+		"unrecognised_url": {src: srv.URL + "/about.html", exp: "<p>about</p>"},
+		"not_found":        {src: srv.URL + "/missing.html", err: "404 Not Found"},
+		"revision":         {src: srv.URL + "/about.html", args: sourceArgs{Revision: 1}, err: "no article title in url"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			page, err := tc.args.openArticle(tc.src)
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.err)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, page.HTML)
+			assert.Equal(t, tc.src, page.URL)
+		})
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	tests := map[string]struct {
+		usage    deepl.Usage
+		usageErr error
+		markdown string
+		err      string
+	}{
+		"enough":        {usage: deepl.Usage{CharacterCount: 0, CharacterLimit: 10}, markdown: "Fire place"},
+		"too_long":      {usage: deepl.Usage{CharacterCount: 1, CharacterLimit: 10}, markdown: "Fire place", err: "article has 10 characters to translate, but only 9 of 10 characters are left"},
+		"markup_exempt": {usage: deepl.Usage{CharacterCount: 4, CharacterLimit: 10}, markdown: "## **Fire** [x](https://example.com/a/long/url)"},
+		"exhausted":     {usage: deepl.Usage{CharacterCount: 12, CharacterLimit: 10}, markdown: "a", err: "only 0 of 10"},
+		"usage_failed":  {usageErr: errors.New("forbidden"), markdown: "a", err: "failed to check quota: forbidden"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := checkQuota(&fakeDeepl{usage: tc.usage, usageErr: tc.usageErr}, tc.markdown)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+}
+
+func TestUsageCmd(t *testing.T) {
+	tests := map[string]struct {
+		usage deepl.Usage
+		exp   string
+	}{
+		"used":      {usage: deepl.Usage{CharacterCount: 1234, CharacterLimit: 500000}, exp: "Characters: 1234 of 500000 (0.2%), 498766 left\n"},
+		"exhausted": {usage: deepl.Usage{CharacterCount: 500001, CharacterLimit: 500000}, exp: "Characters: 500001 of 500000 (100.0%), 0 left\n"},
+		"no_limit":  {usage: deepl.Usage{CharacterCount: 5}, exp: "Characters: 5 of 0 (0.0%), 0 left\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out, err := newUsageCmd(&fakeDeepl{usage: tc.usage})()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, out)
+		})
+	}
+
+	_, err := newUsageCmd(&fakeDeepl{usageErr: errors.New("forbidden")})()
+	assert.Error(t, err)
+}
+
+// fakeLangWiki is a fakeWiki which also knows the language links of its articles by url
+type fakeLangWiki struct {
+	fakeWiki
+	links map[string][]wikipedia.LangLink
+}
+
+func (w fakeLangWiki) LangLinks(ref, defaultLang string) ([]wikipedia.LangLink, error) {
+	links, ok := w.links[ref]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return links, nil
+}
+
+func TestPreferNativeCmd(t *testing.T) {
+	wiki := fakeLangWiki{
+		fakeWiki: fakeWiki{
+			"https://de.wikipedia.org/wiki/Herd":    "<p>Herd</p>",
+			"https://pt.wikipedia.org/wiki/Lareira": "<p>Lareira</p>",
+			"Hearth":                                "<p>hearth</p>",
+		},
+		links: map[string][]wikipedia.LangLink{
+			fakeWikiURL + "Hearth": {
+				{Lang: "de", Title: "Herd", URL: "https://de.wikipedia.org/wiki/Herd"},
+				{Lang: "pt", Title: "Lareira", URL: "https://pt.wikipedia.org/wiki/Lareira"},
+			},
+			"https://de.wikipedia.org/wiki/Herd": {
+				{Lang: "en", Title: "Hearth", URL: fakeWikiURL + "Hearth"},
+			},
+		},
+	}
+
+	hearth := &wikipedia.Page{URL: fakeWikiURL + "Hearth", HTML: "<p>hearth</p>"}
+	tests := map[string]struct {
+		page    *wikipedia.Page
+		tgtLang string
+		exp     string
+		// translated is whether the article is expected to be translated with DeepL
+		translated bool
+		err        string
+	}{
+		"native":       {page: hearth, tgtLang: "DE", exp: "Herd"},
+		"no_native":    {page: hearth, tgtLang: "FR", exp: "translated", translated: true},
+		"en_gb":        {page: &wikipedia.Page{URL: "https://de.wikipedia.org/wiki/Herd", HTML: "<p>Herd</p>"}, tgtLang: "EN-GB", exp: "hearth"},
+		"pt_br":        {page: hearth, tgtLang: "PT-BR", exp: "Lareira"},
+		"stdin":        {page: &wikipedia.Page{HTML: "<html><head><link rel=\"canonical\" href=\"" + fakeWikiURL + "Hearth\"></head><body><p>hearth</p></body></html>"}, tgtLang: "DE", exp: "Herd"},
+		"stdin_no_url": {page: &wikipedia.Page{HTML: "<p>hearth</p>"}, tgtLang: "DE", err: "the url of the article is required"},
+		"no_links":     {page: &wikipedia.Page{URL: fakeWikiURL + "Ash", HTML: "<p>ash</p>"}, tgtLang: "DE", err: "failed to get languages of article"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			translated := false
+			translate := func(page *wikipedia.Page, tgtLang, srcLang string) (string, error) {
+				translated = true
+				return "translated", nil
+			}
+
+			out, err := newPreferNativeCmd(wikipedia.NewArticleParser(), wiki, translate)(tc.page, tc.tgtLang, "")
+			assert.Equal(t, tc.translated, translated)
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.err)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Contains(t, out, tc.exp)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package wikipedia
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Article is the document model of a parsed article. Headings, blocks and footnotes hold markdown.
+type Article struct {
+	Metadata    Metadata
+	Translation *Translation
+	// Infobox is only parsed if the infobox is rendered
+	Infobox Infobox
+	// Lead holds the blocks before the first heading
+	Lead     []Block
+	Sections []*Section
+	// Footnotes holds the text of the footnote [^n] at index n-1
+	Footnotes []string
+	// LinkRefs holds the target of the reference-style link [n] at index n-1
+	LinkRefs []string
+	// Links holds the urls of all articles on the same wiki the article links to, regardless of the link mode
+	Links []string
+}
+
+// Section is a heading together with its content. Subsections are nested in to their parent section.
+type Section struct {
+	Heading  string
+	Level    int
+	Blocks   []Block
+	Sections []*Section
+}
+
+// BlockKind is the type of content of a Block
+type BlockKind int
+
+const (
+	Paragraph BlockKind = iota
+	List
+	DefinitionList
+	Quote
+	Table
+	Image
+)
+
+var blockKindNames = []string{"paragraph", "list", "definition-list", "quote", "table", "image"}
+
+func (k BlockKind) String() string {
+	return blockKindNames[k]
+}
+
+// Block is a single piece of content of a section like a paragraph or a table
+type Block struct {
+	Kind     BlockKind
+	Markdown string
+}
+
+// Markdown renders the section including its subsections
+func (s *Section) Markdown() string {
+	sb := strings.Builder{}
+	sb.WriteString(strings.Repeat("#", s.Level) + " " + s.Heading + "\n\n")
+	writeBlocks(&sb, s.Blocks)
+	for _, sub := range s.Sections {
+		sb.WriteString(sub.Markdown())
+	}
+
+	return sb.String()
+}
+
+// Render renders the article as markdown document, see RenderFrontMatter and RenderBody
+func (p *ArticleParser) Render(a *Article) string {
+	return p.RenderFrontMatter(a) + p.RenderBody(a)
+}
+
+// RenderFrontMatter renders the front matter of the article according to the options of the parser. It returns an
+// empty string if there is none.
+func (p *ArticleParser) RenderFrontMatter(a *Article) string {
+	var fm frontMatter
+	if p.frontMatter {
+		fm = append(fm, a.Metadata.frontMatter()...)
+		if a.Translation != nil {
+			fm = append(fm, a.Translation.frontMatter(a.Metadata.Language)...)
+		}
+	}
+
+	if p.infobox == InfoboxFrontMatter && len(a.Infobox) > 0 {
+		fm = append(fm, frontMatterField{"infobox", a.Infobox.frontMatter()})
+	}
+
+	if len(fm) == 0 {
+		return ""
+	}
+
+	return fm.String()
+}
+
+// RenderBody renders the article without its front matter
+func (p *ArticleParser) RenderBody(a *Article) string {
+	sb := strings.Builder{}
+	sb.Grow(256000)
+
+	if a.Metadata.Title != "" {
+		sb.WriteString("# " + a.Metadata.Title + "\n\n")
+	}
+
+	if p.infobox == InfoboxSummary {
+		sb.WriteString(a.Infobox.summary())
+	}
+
+	writeBlocks(&sb, a.Lead)
+	for _, s := range a.Sections {
+		sb.WriteString(s.Markdown())
+	}
+
+	writeDefinitions(&sb, "^", a.Footnotes)
+	writeDefinitions(&sb, "", a.LinkRefs)
+
+	return sb.String()
+}
+
+func writeBlocks(sb *strings.Builder, blocks []Block) {
+	for _, b := range blocks {
+		sb.WriteString(b.Markdown)
+	}
+}
+
+// writeDefinitions renders footnote (prefix "^") or link reference definitions, numbered from 1
+func writeDefinitions(sb *strings.Builder, prefix string, defs []string) {
+	if len(defs) == 0 {
+		return
+	}
+
+	for i, def := range defs {
+		sb.WriteString("[" + prefix + strconv.Itoa(i+1) + "]: " + def + "\n")
+	}
+	sb.WriteString("\n")
+}
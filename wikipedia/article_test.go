@@ -0,0 +1,67 @@
+package wikipedia
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+const testArticle = "<h1 id=\"firstHeading\">Title</h1><div class=\"mw-parser-output\">" +
+	"<p>lead</p><h2>A</h2><p>a</p><h3>A1</h3><ul><li>item</li></ul><h4>A1a</h4><dl><dt>term</dt></dl>" +
+	"<h3>A2</h3><blockquote><p>quote</p></blockquote>" +
+	"<div class=\"mw-heading mw-heading2\"><h2>B</h2></div><table class=\"wikitable\"><tr><th>T</th></tr></table></div>"
+
+func TestParseArticle(t *testing.T) {
+	p := NewArticleParser()
+	a, err := p.ParseArticle(io.NopCloser(strings.NewReader(testArticle)))
+	assert.NoError(t, err)
+
+	exp := &Article{
+		Metadata: Metadata{Title: "Title", Retrieved: a.Metadata.Retrieved},
+		Lead:     []Block{{Paragraph, "lead\n\n"}},
+		Sections: []*Section{
+			{Heading: "A", Level: 2, Blocks: []Block{{Paragraph, "a\n\n"}}, Sections: []*Section{
+				{Heading: "A1", Level: 3, Blocks: []Block{{List, "- item\n\n"}}, Sections: []*Section{
+					{Heading: "A1a", Level: 4, Blocks: []Block{{DefinitionList, "**term**\n\n"}}},
+				}},
+				{Heading: "A2", Level: 3, Blocks: []Block{{Quote, "> quote\n\n"}}},
+			}},
+			{Heading: "B", Level: 2, Blocks: []Block{{Table, "| T |\n| --- |\n\n"}}},
+		},
+	}
+
+	assert.Equal(t, exp, a)
+	assert.Equal(t, "### A2\n\n> quote\n\n", a.Sections[0].Sections[1].Markdown())
+}
+
+func TestRender(t *testing.T) {
+	a := &Article{
+		Metadata:  Metadata{Title: "Title"},
+		Infobox:   Infobox{{"Label", "Value"}},
+		Lead:      []Block{{Paragraph, "lead[^1]\n\n"}},
+		Sections:  []*Section{{Heading: "A", Level: 2, Blocks: []Block{{Paragraph, "[a][1]\n\n"}}}},
+		Footnotes: []string{"note"},
+		LinkRefs:  []string{"https://example.com"},
+	}
+
+	tests := map[string]struct {
+		opts              []Option
+		frontMatter, body string
+	}{
+		"default": {body: "# Title\n\nlead[^1]\n\n## A\n\n[a][1]\n\n[^1]: note\n\n[1]: https://example.com\n\n"},
+		"infobox_summary": {opts: []Option{WithInfobox(InfoboxSummary)},
+			body: "# Title\n\n- **Label:** Value\n\nlead[^1]\n\n## A\n\n[a][1]\n\n[^1]: note\n\n[1]: https://example.com\n\n"},
+		"infobox_front_matter": {opts: []Option{WithInfobox(InfoboxFrontMatter)}, frontMatter: "---\ninfobox:\n  Label: \"Value\"\n---\n\n",
+			body: "# Title\n\nlead[^1]\n\n## A\n\n[a][1]\n\n[^1]: note\n\n[1]: https://example.com\n\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := NewArticleParser(tc.opts...)
+			assert.Equal(t, tc.frontMatter, p.RenderFrontMatter(a))
+			assert.Equal(t, tc.body, p.RenderBody(a))
+			assert.Equal(t, tc.frontMatter+tc.body, p.Render(a))
+		})
+	}
+}
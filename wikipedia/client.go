@@ -0,0 +1,461 @@
+package wikipedia
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Client fetches articles through the MediaWiki action api (https://www.mediawiki.org/wiki/API:Main_page). The api
+// endpoint is derived from the host of the page url, so every wiki which uses the default /w/api.php location is
+// supported.
+type Client interface {
+	// Fetch returns the article ref, which is either a page url (https://en.wikipedia.org/wiki/Hearth), a title prefixed
+	// with the language of its wikipedia (en:Hearth) or a title on the wikipedia of defaultLang. Redirects are followed.
+	// The latest revision is returned unless revision or the oldid parameter of the url is set.
+	Fetch(ref, defaultLang string, revision int) (*Page, error)
+	// LangLinks returns the versions of the article ref in the wikipedias of other languages, see Fetch for ref
+	LangLinks(ref, defaultLang string) ([]LangLink, error)
+	// CategoryMembers returns the articles of the category ref (e.g. en:Category:Cooking appliances), see Fetch for ref.
+	// Articles of subcategories are included up to the given depth (0 for none). At most limit articles are returned,
+	// unless limit is 0.
+	CategoryMembers(ref, defaultLang string, depth, limit int) ([]CategoryMember, error)
+}
+
+// ErrNoArticleURL is returned by Client.Fetch for urls which don't name an article, neither by their path (/wiki/Title)
+// nor by the title or oldid parameter
+var ErrNoArticleURL = errors.New("unrecognised url")
+
+// Page is an article as returned by the api
+type Page struct {
+	// Title is the canonical title of the page after following redirects
+	Title string
+	// URL is the canonical url of the page or its permanent link if a specific revision was requested
+	URL      string
+	Language string
+	Revision int
+	HTML     string
+}
+
+type client struct {
+	client *http.Client
+	// wikipedia returns the wiki for a language code
+	wikipedia func(lang string) wiki
+}
+
+// NewClient returns a Client which sends its requests using httpClient
+func NewClient(httpClient *http.Client) Client {
+	return &client{client: httpClient, wikipedia: func(lang string) wiki {
+		return wiki{"https", lang + ".wikipedia.org"}
+	}}
+}
+
+// LangLink is a version of an article in another language
+type LangLink struct {
+	// Lang is the language code of the wiki (e.g. de)
+	Lang string
+	// Name is the english name of the language
+	Name  string
+	Title string
+	URL   string
+}
+
+// CategoryMember is an article of a category
+type CategoryMember struct {
+	Title string
+	URL   string
+}
+
+// wiki identifies a MediaWiki installation by the scheme and host of its urls
+type wiki struct {
+	scheme, host string
+}
+
+func (w wiki) apiURL() string {
+	return w.scheme + "://" + w.host + "/w/api.php"
+}
+
+// language returns the language of a wikipedia (de.wikipedia.org, de.m.wikipedia.org) or "" for other wikis
+func (w wiki) language() string {
+	if !strings.HasSuffix(w.host, ".wikipedia.org") {
+		return ""
+	}
+
+	return strings.SplitN(w.host, ".", 2)[0]
+}
+
+// pageURL returns the canonical url of the page with the given title
+func (w wiki) pageURL(title string) string {
+	return w.scheme + "://" + w.host + "/wiki/" + titlePath(title)
+}
+
+// titlePath returns title in the (escaped) form used in the path of article urls
+func titlePath(title string) string {
+	return strings.ReplaceAll(url.PathEscape(strings.ReplaceAll(title, " ", "_")), "%2F", "/")
+}
+
+// permalink returns the url of a specific revision of a page
+func (w wiki) permalink(title string, revision int) string {
+	params := url.Values{}
+	params.Set("title", strings.ReplaceAll(title, " ", "_"))
+	params.Set("oldid", strconv.Itoa(revision))
+
+	return w.scheme + "://" + w.host + "/w/index.php?" + params.Encode()
+}
+
+// articleRef identifies an article (or one of its revisions) on a wiki
+type articleRef struct {
+	wiki     wiki
+	title    string
+	revision int
+}
+
+// parsePageURL parses an article url. Both the short (/wiki/Title) and the long (/w/index.php?title=Title) form are
+// understood, the latter may pin a revision with oldid, in which case the title is optional.
+func parsePageURL(pageURL string) (articleRef, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return articleRef{}, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return articleRef{}, fmt.Errorf("invalid article url %q", pageURL)
+	}
+
+	// the query is already unescaped, the path is unescaped by NormalizeTitle
+	title := cleanTitle(u.Query().Get("title"))
+	if strings.HasPrefix(u.Path, "/wiki/") {
+		title = NormalizeTitle(strings.TrimPrefix(u.EscapedPath(), "/wiki/"))
+	}
+
+	var revision int
+	if oldid := u.Query().Get("oldid"); oldid != "" {
+		revision, err = strconv.Atoi(oldid)
+		if err != nil || revision <= 0 {
+			return articleRef{}, fmt.Errorf("invalid revision %q in url %q", oldid, pageURL)
+		}
+	}
+
+	if title == "" && revision == 0 {
+		return articleRef{}, fmt.Errorf("%w: no article title in url %q", ErrNoArticleURL, pageURL)
+	}
+
+	return articleRef{wiki{u.Scheme, u.Host}, title, revision}, nil
+}
+
+// resolveRef parses an article reference as accepted by Fetch. A revision other than 0 overrides the one in the url.
+func (c *client) resolveRef(ref, defaultLang string, revision int) (articleRef, error) {
+	if revision < 0 {
+		return articleRef{}, fmt.Errorf("invalid revision %d", revision)
+	}
+
+	if strings.Contains(ref, "://") {
+		r, err := parsePageURL(ref)
+		if revision != 0 {
+			r.revision = revision
+		}
+
+		return r, err
+	}
+
+	lang, title, ok := splitLangPrefix(ref)
+	if !ok {
+		lang = defaultLang
+	}
+
+	title = NormalizeTitle(title)
+	if title == "" {
+		return articleRef{}, fmt.Errorf("invalid article %q", ref)
+	}
+
+	if lang == "" {
+		return articleRef{}, fmt.Errorf("no language given for article %q", ref)
+	}
+
+	return articleRef{c.wikipedia(lang), title, revision}, nil
+}
+
+// WikiLanguage converts a language code as used by DeepL (e.g. EN-GB, PT-BR) to the code of the corresponding wikipedia
+func WikiLanguage(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.Index(lang, "-"); i != -1 {
+		lang = lang[:i]
+	}
+
+	// Norwegian Bokmål is written on the "no" wikipedia
+	if lang == "nb" {
+		return "no"
+	}
+
+	return lang
+}
+
+// NormalizeTitle converts a title as found in urls (Warentrenner_%28Kasse%29) in to its display form (Warentrenner (Kasse)).
+// Percent-encoding is decoded, underscores are replaced by spaces and runs of whitespace are collapsed.
+func NormalizeTitle(title string) string {
+	if unescaped, err := url.PathUnescape(title); err == nil {
+		title = unescaped
+	}
+
+	return cleanTitle(title)
+}
+
+// cleanTitle converts an unescaped title in to its display form, see NormalizeTitle
+func cleanTitle(title string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(title, "_", " ")), " ")
+}
+
+type parseResponse struct {
+	Parse struct {
+		Title string `json:"title"`
+		RevID int    `json:"revid"`
+		Text  string `json:"text"`
+	} `json:"parse"`
+}
+
+// Fetch returns the article ref using action=parse. The output of the legacy parser is requested as it is the same html
+// which is served to readers of the page.
+func (c *client) Fetch(ref, defaultLang string, revision int) (*Page, error) {
+	r, err := c.resolveRef(ref, defaultLang, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("action", "parse")
+	params.Set("prop", "text|revid")
+	params.Set("disableeditsection", "1")
+	if r.revision != 0 {
+		params.Set("oldid", strconv.Itoa(r.revision))
+	} else {
+		params.Set("page", r.title)
+		params.Set("redirects", "1")
+	}
+
+	resp, err := apiGet[parseResponse](c, r.wiki, params)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page{
+		Title:    resp.Parse.Title,
+		URL:      r.wiki.pageURL(resp.Parse.Title),
+		Language: r.wiki.language(),
+		Revision: resp.Parse.RevID,
+		HTML:     resp.Parse.Text,
+	}
+
+	// pinned revisions are cited by their permanent link
+	if r.revision != 0 {
+		page.URL = r.wiki.permalink(page.Title, page.Revision)
+	}
+
+	return page, nil
+}
+
+type langLinksResponse struct {
+	Query struct {
+		Pages []struct {
+			Title     string `json:"title"`
+			Missing   bool   `json:"missing"`
+			LangLinks []struct {
+				Lang     string `json:"lang"`
+				LangName string `json:"langname"`
+				Title    string `json:"title"`
+				URL      string `json:"url"`
+			} `json:"langlinks"`
+		} `json:"pages"`
+	} `json:"query"`
+}
+
+// LangLinks returns the interlanguage links of the article ref using prop=langlinks
+func (c *client) LangLinks(ref, defaultLang string) ([]LangLink, error) {
+	r, err := c.resolveRef(ref, defaultLang, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("prop", "langlinks")
+	params.Set("redirects", "1")
+	if r.revision != 0 {
+		params.Set("revids", strconv.Itoa(r.revision))
+	} else {
+		params.Set("titles", r.title)
+	}
+	params.Set("llprop", "url|langname")
+	params.Set("lllimit", "max")
+
+	var links []LangLink
+	err = apiQueryAll(c, r.wiki, params, func(resp langLinksResponse) error {
+		for _, page := range resp.Query.Pages {
+			if page.Missing {
+				return fmt.Errorf("missingtitle: The page %q doesn't exist.", page.Title)
+			}
+
+			for _, l := range page.LangLinks {
+				links = append(links, LangLink{l.Lang, l.LangName, l.Title, l.URL})
+			}
+		}
+
+		return nil
+	})
+
+	return links, err
+}
+
+type categoryMembersResponse struct {
+	Query struct {
+		Members []struct {
+			Namespace int    `json:"ns"`
+			Title     string `json:"title"`
+		} `json:"categorymembers"`
+	} `json:"query"`
+}
+
+// errLimitReached stops apiQueryAll once enough category members were collected
+var errLimitReached = errors.New("limit reached")
+
+// CategoryMembers returns the articles of a category using list=categorymembers. Categories are traversed breadth-first,
+// so the articles closest to the category are kept if the limit is reached.
+func (c *client) CategoryMembers(ref, defaultLang string, depth, limit int) ([]CategoryMember, error) {
+	r, err := c.resolveRef(ref, defaultLang, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []CategoryMember
+	seen := map[string]bool{}
+	visited := map[string]bool{r.title: true}
+	categories := []string{r.title}
+
+	for level := 0; len(categories) > 0 && level <= depth; level++ {
+		var subcategories []string
+		for _, category := range categories {
+			params := url.Values{}
+			params.Set("list", "categorymembers")
+			params.Set("cmtitle", category)
+			params.Set("cmnamespace", "0|14")
+			params.Set("cmlimit", "max")
+
+			err = apiQueryAll(c, r.wiki, params, func(resp categoryMembersResponse) error {
+				for _, m := range resp.Query.Members {
+					switch {
+					case m.Namespace == 14 && !visited[m.Title]:
+						visited[m.Title] = true
+						subcategories = append(subcategories, m.Title)
+					case m.Namespace == 0 && !seen[m.Title]:
+						seen[m.Title] = true
+						members = append(members, CategoryMember{m.Title, r.wiki.pageURL(m.Title)})
+						if limit > 0 && len(members) >= limit {
+							return errLimitReached
+						}
+					}
+				}
+
+				return nil
+			})
+
+			if err == errLimitReached {
+				return members, nil
+			}
+
+			if err != nil {
+				return nil, err
+			}
+		}
+		categories = subcategories
+	}
+
+	return members, nil
+}
+
+// apiQueryAll sends action=query requests to the api of w and calls fn with every response until all continuations are
+// consumed
+func apiQueryAll[R any](c *client, w wiki, params url.Values, fn func(R) error) error {
+	params.Set("action", "query")
+	for {
+		raw, err := apiGet[json.RawMessage](c, w, params)
+		if err != nil {
+			return err
+		}
+
+		var parsed R
+		var cont struct {
+			Continue map[string]interface{} `json:"continue"`
+		}
+		if err := json.Unmarshal(raw, &parsed); err != nil {
+			return fmt.Errorf("%s (occurred while parse response)", err)
+		}
+		if err := json.Unmarshal(raw, &cont); err != nil {
+			return fmt.Errorf("%s (occurred while parse response)", err)
+		}
+
+		if err := fn(parsed); err != nil {
+			return err
+		}
+
+		if len(cont.Continue) == 0 {
+			return nil
+		}
+
+		for k, v := range cont.Continue {
+			params.Set(k, fmt.Sprint(v))
+		}
+	}
+}
+
+// apiError is returned by the api with a status of 200
+type apiError struct {
+	Code string `json:"code"`
+	Info string `json:"info"`
+}
+
+// apiGet sends a GET request to the api of w and parses the json response in to R
+func apiGet[R any](c *client, w wiki, params url.Values) (R, error) {
+	var parsed R
+
+	params.Set("format", "json")
+	params.Set("formatversion", "2")
+	req, err := http.NewRequest(http.MethodGet, w.apiURL()+"?"+params.Encode(), nil)
+	if err != nil {
+		return parsed, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return parsed, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return parsed, fmt.Errorf("unexpected status %s from %s", resp.Status, w.apiURL())
+	}
+
+	var body struct {
+		Error *apiError `json:"error"`
+	}
+	raw := json.RawMessage{}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return parsed, fmt.Errorf("%s (occurred while parse response)", err)
+	}
+
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return parsed, fmt.Errorf("%s (occurred while parse response)", err)
+	}
+
+	if body.Error != nil {
+		return parsed, errors.New(body.Error.Code + ": " + body.Error.Info)
+	}
+
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return parsed, fmt.Errorf("%s (occurred while parse response)", err)
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,297 @@
+package wikipedia
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// testWiki is a stand-in for the api of the wikipedias of all languages
+type testWiki struct {
+	*httptest.Server
+	// client sends the requests for all wikipedias to the server
+	client *client
+	// lang is the language of the wikipedia last requested through client
+	lang string
+}
+
+// newTestWiki starts a testWiki, which is closed at the end of the test. handle returns the response to a request or
+// nil if the page does not exist.
+func newTestWiki(t *testing.T, handle func(q url.Values) interface{}) *testWiki {
+	w := &testWiki{Server: httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/w/api.php", r.URL.Path)
+		assert.Equal(t, UserAgent, r.Header.Get("User-Agent"))
+		q := r.URL.Query()
+		assert.Equal(t, "json", q.Get("format"))
+		assert.Equal(t, "2", q.Get("formatversion"))
+
+		resp := handle(q)
+		if resp == nil {
+			resp = map[string]interface{}{"error": map[string]string{
+				"code": "missingtitle", "info": "The page you specified doesn't exist."}}
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	}))}
+	t.Cleanup(w.Close)
+
+	u, _ := url.Parse(w.URL)
+	w.client = NewClient(w.Client()).(*client)
+	w.client.wikipedia = func(lang string) wiki {
+		w.lang = lang
+		return wiki{u.Scheme, u.Host}
+	}
+
+	return w
+}
+
+// testParse returns the response to action=parse for an article
+func testParse(title string, revision int, html string) map[string]interface{} {
+	return map[string]interface{}{"parse": map[string]interface{}{"title": title, "revid": revision,
+		"text": "<div class=\"mw-content-ltr mw-parser-output\" lang=\"en\">" + html + "</div>"}}
+}
+
+func TestClientFetch(t *testing.T) {
+	srv := newTestWiki(t, func(q url.Values) interface{} {
+		revision := 42
+		switch {
+		case q.Get("action") != "parse":
+			return nil
+		case q.Get("oldid") == "41":
+			assert.Empty(t, q.Get("page"))
+			revision = 41
+		case q.Get("oldid") != "":
+			return map[string]interface{}{"error": map[string]string{"code": "nosuchrevid", "info": "There is no revision with ID " + q.Get("oldid") + "."}}
+		case q.Get("page") != "Fire place":
+			return nil
+		}
+
+		return testParse("Hearth", revision, "<p><a href=\"/wiki/Fire\">Fire</a></p>")
+	})
+
+	tests := map[string]struct {
+		ref, defaultLang string
+		revision         int
+		lang             string
+		pinned           bool
+		err              string
+	}{
+		"short":             {ref: srv.URL + "/wiki/Fire_place"},
+		"long":              {ref: srv.URL + "/w/index.php?title=Fire_place&action=view"},
+		"escaped":           {ref: srv.URL + "/wiki/Fire%20place"},
+		"title":             {ref: "Fire place", defaultLang: "en", lang: "en"},
+		"title_prefixed":    {ref: "de:Fire_place", defaultLang: "en", lang: "de"},
+		"title_escaped":     {ref: "simple:Fire%20place", lang: "simple"},
+		"title_spaces":      {ref: "  Fire   place ", defaultLang: "en", lang: "en"},
+		"missing":           {ref: srv.URL + "/wiki/Missing", err: "missingtitle: The page you specified doesn't exist."},
+		"no_title":          {ref: srv.URL + "/about", err: "no article title in url"},
+		"no_http":           {ref: "ftp://example.com/wiki/Hearth", err: "invalid article url"},
+		"no_language":       {ref: "Fire place", err: "no language given"},
+		"empty_title":       {ref: "de: ", defaultLang: "en", err: "invalid article"},
+		"revision":          {ref: "Fire place", defaultLang: "en", revision: 41, lang: "en", pinned: true},
+		"revision_url":      {ref: srv.URL + "/w/index.php?title=Fire_place&oldid=41", pinned: true},
+		"revision_only":     {ref: srv.URL + "/w/index.php?oldid=41", pinned: true},
+		"revision_flag":     {ref: srv.URL + "/w/index.php?oldid=40", revision: 41, pinned: true},
+		"revision_missing":  {ref: "en:Fire place", revision: 7, err: "nosuchrevid"},
+		"revision_invalid":  {ref: srv.URL + "/w/index.php?oldid=abc", err: "invalid revision"},
+		"revision_negative": {ref: "en:Fire place", revision: -1, err: "invalid revision"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv.lang = ""
+			page, err := srv.client.Fetch(tc.ref, tc.defaultLang, tc.revision)
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tc.err)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.lang, srv.lang)
+			assert.Equal(t, "Hearth", page.Title)
+			if tc.pinned {
+				assert.Equal(t, srv.URL+"/w/index.php?oldid=41&title=Hearth", page.URL)
+				assert.Equal(t, 41, page.Revision)
+			} else {
+				assert.Equal(t, srv.URL+"/wiki/Hearth", page.URL)
+				assert.Equal(t, 42, page.Revision)
+			}
+
+			p := NewArticleParser(WithLinkMode(LinkInline))
+			a, err := p.ParsePage(page)
+			assert.NoError(t, err)
+			assert.Equal(t, "en", a.Metadata.Language)
+			assert.Equal(t, "# Hearth\n\n[Fire]("+srv.URL+"/wiki/Fire)\n\n", p.RenderBody(a))
+		})
+	}
+
+	_, err := srv.client.Fetch(srv.URL+"/about", "", 0)
+	assert.True(t, errors.Is(err, ErrNoArticleURL))
+}
+
+func TestClientLangLinks(t *testing.T) {
+	srv := newTestWiki(t, func(q url.Values) interface{} {
+		if q.Get("action") != "query" || q.Get("prop") != "langlinks" {
+			return nil
+		}
+
+		switch q.Get("titles") {
+		case "Hearth":
+			if q.Get("llcontinue") == "" {
+				return map[string]interface{}{
+					"continue": map[string]string{"llcontinue": "1|fr", "continue": "||"},
+					"query": map[string]interface{}{"pages": []interface{}{map[string]interface{}{"title": "Hearth",
+						"langlinks": []interface{}{map[string]string{"lang": "de", "langname": "German", "title": "Herd", "url": "https://de.wikipedia.org/wiki/Herd"}}}}},
+				}
+			}
+
+			assert.Equal(t, "1|fr", q.Get("llcontinue"))
+			return map[string]interface{}{
+				"query": map[string]interface{}{"pages": []interface{}{map[string]interface{}{"title": "Hearth",
+					"langlinks": []interface{}{map[string]string{"lang": "fr", "langname": "French", "title": "Âtre", "url": "https://fr.wikipedia.org/wiki/%C3%82tre"}}}}},
+			}
+		case "Missing":
+			return map[string]interface{}{
+				"query": map[string]interface{}{"pages": []interface{}{map[string]interface{}{"title": "Missing", "missing": true}}},
+			}
+		}
+
+		return nil
+	})
+
+	c := srv.client
+
+	links, err := c.LangLinks("en:Hearth", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []LangLink{
+		{"de", "German", "Herd", "https://de.wikipedia.org/wiki/Herd"},
+		{"fr", "French", "Âtre", "https://fr.wikipedia.org/wiki/%C3%82tre"},
+	}, links)
+
+	_, err = c.LangLinks("en:Missing", "")
+	assert.Error(t, err)
+}
+
+func TestClientCategoryMembers(t *testing.T) {
+	member := func(ns int, title string) map[string]interface{} {
+		return map[string]interface{}{"ns": ns, "title": title}
+	}
+	categories := map[string][][]interface{}{
+		// pages of the response, split by continuation
+		"Category:A": {{member(0, "P1"), member(14, "Category:B")}, {member(0, "P2")}},
+		"Category:B": {{member(0, "P3"), member(0, "P1"), member(14, "Category:A"), member(14, "Category:C")}},
+		"Category:C": {{member(0, "P4"), member(6, "File:F.png")}},
+	}
+
+	srv := newTestWiki(t, func(q url.Values) interface{} {
+		assert.Equal(t, "categorymembers", q.Get("list"))
+		pages := categories[q.Get("cmtitle")]
+		page := 0
+		if q.Get("cmcontinue") != "" {
+			page = 1
+		}
+
+		resp := map[string]interface{}{"query": map[string]interface{}{"categorymembers": pages[page]}}
+		if page+1 < len(pages) {
+			resp["continue"] = map[string]string{"cmcontinue": "next", "continue": "-||"}
+		}
+
+		return resp
+	})
+
+	c := srv.client
+
+	tests := map[string]struct {
+		depth, limit int
+		exp          []string
+	}{
+		"direct":    {depth: 0, exp: []string{"P1", "P2"}},
+		"depth_1":   {depth: 1, exp: []string{"P1", "P2", "P3"}},
+		"depth_2":   {depth: 2, exp: []string{"P1", "P2", "P3", "P4"}},
+		"depth_10":  {depth: 10, exp: []string{"P1", "P2", "P3", "P4"}},
+		"limit":     {depth: 2, limit: 3, exp: []string{"P1", "P2", "P3"}},
+		"limit_one": {depth: 0, limit: 1, exp: []string{"P1"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			members, err := c.CategoryMembers("Category:A", "en", tc.depth, tc.limit)
+			assert.NoError(t, err)
+
+			var titles []string
+			for _, m := range members {
+				titles = append(titles, m.Title)
+				assert.Equal(t, srv.URL+"/wiki/"+m.Title, m.URL)
+			}
+			assert.Equal(t, tc.exp, titles)
+		})
+	}
+}
+
+func TestWikiLanguage(t *testing.T) {
+	tests := map[string]string{"DE": "de", "EN-GB": "en", "pt-br": "pt", "NB": "no", "ZH": "zh"}
+
+	for in, exp := range tests {
+		t.Run(in, func(t *testing.T) {
+			assert.Equal(t, exp, WikiLanguage(in))
+		})
+	}
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := map[string]string{
+		"Hearth":                                     "Hearth",
+		"Warentrenner_%28Kasse%29":                   "Warentrenner (Kasse)",
+		" Star_Wars:  Episode_IV ":                   "Star Wars: Episode IV",
+		"100%_invalid_escape":                        "100% invalid escape",
+		"%D0%A3%D0%BA%D1%80%D0%B0%D1%97%D0%BD%D0%B0": "Україна",
+	}
+
+	for in, exp := range tests {
+		t.Run(in, func(t *testing.T) {
+			assert.Equal(t, exp, NormalizeTitle(in))
+		})
+	}
+}
+
+func TestWikiHostLanguage(t *testing.T) {
+	tests := map[string]string{"de.wikipedia.org": "de", "de.m.wikipedia.org": "de", "wiki.example.com": ""}
+
+	for host, exp := range tests {
+		t.Run(host, func(t *testing.T) {
+			assert.Equal(t, exp, wiki{"https", host}.language())
+		})
+	}
+}
+
+func TestResolveRef(t *testing.T) {
+	c := NewClient(nil).(*client)
+
+	tests := map[string]struct {
+		ref, host, title string
+	}{
+		"prefix":          {ref: "de:Herd", host: "de.wikipedia.org", title: "Herd"},
+		"prefix_dialect":  {ref: "zh-yue:Foo", host: "zh-yue.wikipedia.org", title: "Foo"},
+		"no_language":     {ref: "abc:Foo", host: "en.wikipedia.org", title: "abc:Foo"},
+		"namespace":       {ref: "Category:Fire", host: "en.wikipedia.org", title: "Category:Fire"},
+		"path_escaped":    {ref: "https://en.wikipedia.org/wiki/A%2541", host: "en.wikipedia.org", title: "A%41"},
+		"query_escaped":   {ref: "https://en.wikipedia.org/w/index.php?title=A%2541", host: "en.wikipedia.org", title: "A%41"},
+		"query_percent":   {ref: "https://en.wikipedia.org/w/index.php?title=100%25_pure", host: "en.wikipedia.org", title: "100% pure"},
+		"query_separator": {ref: "https://en.wikipedia.org/w/index.php?title=Fire_place", host: "en.wikipedia.org", title: "Fire place"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := c.resolveRef(tc.ref, "en", 0)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.host, r.wiki.host)
+			assert.Equal(t, tc.title, r.title)
+		})
+	}
+}
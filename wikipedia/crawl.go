@@ -0,0 +1,115 @@
+package wikipedia
+
+import (
+	"net/url"
+	"strings"
+)
+
+// CrawledPage is an article found by Crawl
+type CrawledPage struct {
+	*Page
+	// Hops is the number of links between the start article and this one
+	Hops int
+	// Ref is the reference the page was fetched by, e.g. the url of the first link to it
+	Ref string
+	// Err is set if the page could not be fetched, Page is nil then
+	Err error
+}
+
+// CrawlResult holds all articles found by Crawl in the order they were fetched
+type CrawlResult struct {
+	Pages []*CrawledPage
+	// index maps the keys of all urls a page was requested by (including redirects) to the page
+	index map[string]*CrawledPage
+}
+
+// Lookup returns the crawled page at the url target or nil if it was not crawled. Different spellings of the url (e.g.
+// underscores instead of spaces) and redirects are resolved.
+func (r *CrawlResult) Lookup(target string) *CrawledPage {
+	key := pageKey(target)
+	if key == "" {
+		return nil
+	}
+
+	return r.index[key]
+}
+
+func (r *CrawlResult) add(pageURL string, cp *CrawledPage) {
+	if key := pageKey(pageURL); key != "" {
+		r.index[key] = cp
+	}
+}
+
+// pageKey returns an identifier of the article at pageURL which is the same for all spellings of the url, or "" if
+// pageURL is no article url
+func pageKey(pageURL string) string {
+	u, err := url.Parse(pageURL)
+	if err != nil || !strings.HasPrefix(u.EscapedPath(), "/wiki/") {
+		return ""
+	}
+
+	return u.Host + "/" + NormalizeTitle(strings.TrimPrefix(u.EscapedPath(), "/wiki/"))
+}
+
+// Crawl fetches the article ref (see Client.Fetch) and all articles it links to, up to hops links away. Pages are
+// fetched breadth-first, at most limit pages are fetched unless limit is 0. Links are taken from the articles as parsed
+// by p, so e.g. only links of selected sections are followed. Articles which fail to be fetched are part of the result
+// with their error set, only if the start article fails an error is returned.
+func Crawl(c Client, p *ArticleParser, ref, defaultLang string, hops, limit int) (*CrawlResult, error) {
+	start, err := c.Fetch(ref, defaultLang, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &CrawlResult{index: map[string]*CrawledPage{}}
+	queue := []*CrawledPage{{Page: start, Ref: ref}}
+	queued := map[string]bool{pageKey(start.URL): true}
+
+	for len(queue) > 0 {
+		cp := queue[0]
+		queue = queue[1:]
+
+		if cp.Page == nil {
+			cp.Page, cp.Err = c.Fetch(cp.Ref, "", 0)
+		}
+
+		if cp.Err != nil {
+			cp.Page = nil
+			res.Pages = append(res.Pages, cp)
+			res.add(cp.Ref, cp)
+			continue
+		}
+
+		// a redirect to an article which was crawled already
+		if other := res.Lookup(cp.URL); other != nil {
+			res.add(cp.Ref, other)
+			continue
+		}
+
+		res.Pages = append(res.Pages, cp)
+		res.add(cp.Ref, cp)
+		res.add(cp.URL, cp)
+
+		if cp.Hops >= hops {
+			continue
+		}
+
+		a, err := p.ParsePage(cp.Page)
+		if err != nil {
+			cp.Page, cp.Err = nil, err
+			continue
+		}
+
+		for _, link := range a.Links {
+			key := pageKey(link)
+			if queued[key] || limit > 0 && len(res.Pages)+len(queue) >= limit {
+				continue
+			}
+
+			queued[key] = true
+			queue = append(queue, &CrawledPage{Ref: link, Hops: cp.Hops + 1})
+		}
+	}
+
+	return res, nil
+}
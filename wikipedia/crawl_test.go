@@ -0,0 +1,66 @@
+package wikipedia
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestCrawl(t *testing.T) {
+	pages := map[string]struct {
+		title, html string
+	}{
+		"Hearth": {"Hearth", "<p><a href=\"/wiki/Fire\">fire</a> <a href=\"/wiki/Fires\">fires</a> <a href=\"/wiki/Missing\">missing</a></p>"},
+		"Fire":   {"Fire", "<p><a href=\"/wiki/Hearth\">hearth</a> <a href=\"/wiki/Smoke\">smoke</a></p>"},
+		"Fires":  {"Fire", "<p><a href=\"/wiki/Hearth\">hearth</a> <a href=\"/wiki/Smoke\">smoke</a></p>"},
+		"Smoke":  {"Smoke", "<p><a href=\"/wiki/Ash\">ash</a></p>"},
+		"Ash":    {"Ash", "<p>ash</p>"},
+	}
+
+	srv := newTestWiki(t, func(q url.Values) interface{} {
+		page, ok := pages[q.Get("page")]
+		if !ok {
+			return nil
+		}
+
+		return testParse(page.title, 1, page.html)
+	})
+
+	c := srv.client
+
+	tests := map[string]struct {
+		hops, limit int
+		exp         []string
+	}{
+		"no_hops":  {hops: 0, exp: []string{"Hearth"}},
+		"one_hop":  {hops: 1, exp: []string{"Hearth", "Fire", "!Missing"}},
+		"two_hops": {hops: 2, exp: []string{"Hearth", "Fire", "!Missing", "Smoke"}},
+		"limit":    {hops: 5, limit: 2, exp: []string{"Hearth", "Fire"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := Crawl(c, NewArticleParser(), "en:Hearth", "", tc.hops, tc.limit)
+			assert.NoError(t, err)
+
+			var act []string
+			for _, cp := range res.Pages {
+				if cp.Err != nil {
+					act = append(act, "!"+NormalizeTitle(cp.Ref[len(srv.URL+"/wiki/"):]))
+					continue
+				}
+				act = append(act, cp.Title)
+			}
+			assert.Equal(t, tc.exp, act)
+
+			// the redirect resolves to the same page
+			if tc.hops > 0 && tc.limit == 0 {
+				assert.Same(t, res.Lookup(srv.URL+"/wiki/Fire"), res.Lookup(srv.URL+"/wiki/Fires#History"))
+			}
+			assert.Nil(t, res.Lookup(srv.URL+"/wiki/Ash"))
+		})
+	}
+
+	_, err := Crawl(c, NewArticleParser(), "en:Missing", "", 1, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,195 @@
+package wikipedia
+
+import (
+	"compress/bzip2"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// maxDumpRedirects is the number of redirects followed by Dump.Fetch before giving up
+const maxDumpRedirects = 5
+
+// Dump reads articles from an XML dump of a wiki (e.g. enwiki-latest-pages-articles.xml.bz2). Dumps are not indexed,
+// so every lookup streams the file until the article is found.
+type Dump struct {
+	path string
+}
+
+// NewDump returns a Dump reading the file at path, which is decompressed if it ends with .bz2
+func NewDump(path string) *Dump {
+	return &Dump{path: path}
+}
+
+type dumpSiteInfo struct {
+	Base string `xml:"base"`
+}
+
+type dumpPage struct {
+	Title    string `xml:"title"`
+	Redirect *struct {
+		Title string `xml:"title,attr"`
+	} `xml:"redirect"`
+	Revision struct {
+		ID   int    `xml:"id"`
+		Text string `xml:"text"`
+	} `xml:"revision"`
+}
+
+// Fetch returns the article with the given title, optionally prefixed with the language of the dump (en:Hearth).
+// Redirects are followed, the wikitext of the article is converted to html using WikitextToHTML.
+//
+// Redirects are resolved while streaming: the target of a redirect is looked for in the rest of the dump. Only a target
+// which precedes its redirect in the dump requires another pass.
+func (d *Dump) Fetch(title string) (*Page, error) {
+	lang, t, _ := splitLangPrefix(title)
+	start := dumpTitle(t)
+	if start == "" {
+		return nil, fmt.Errorf("invalid article %q", title)
+	}
+
+	s := &dumpScan{lang: lang, start: start, pages: map[string]*dumpPage{}}
+	for {
+		page, target, err := s.resolve()
+		if err != nil {
+			return nil, err
+		}
+		if page != nil {
+			p := &Page{
+				Title:    page.Title,
+				Language: s.siteLang,
+				Revision: page.Revision.ID,
+				HTML:     WikitextToHTML(page.Revision.Text),
+			}
+			if s.wiki.host != "" {
+				p.URL = s.wiki.pageURL(page.Title)
+			}
+
+			return p, nil
+		}
+
+		if s.scanned[target] {
+			return nil, fmt.Errorf("%q not found in %s", target, d.path)
+		}
+		if err := d.scan(s, target); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// dumpScan holds the pages found while looking for an article and the redirects leading to it
+type dumpScan struct {
+	// lang is the language the article was prefixed with
+	lang  string
+	start string
+	// pages are the pages on the way from start to the article by their normalized title
+	pages map[string]*dumpPage
+	// scanned are the titles a full pass has been made for
+	scanned  map[string]bool
+	wiki     wiki
+	siteLang string
+}
+
+// resolve follows the redirects from the start title through the pages found so far. It returns the article, if it was
+// found, or the title which has to be looked for next.
+func (s *dumpScan) resolve() (*dumpPage, string, error) {
+	title := s.start
+	for i := 0; i <= maxDumpRedirects; i++ {
+		page, ok := s.pages[title]
+		if !ok {
+			return nil, title, nil
+		}
+		if page.Redirect == nil {
+			return page, "", nil
+		}
+		title = dumpTitle(page.Redirect.Title)
+	}
+
+	return nil, "", fmt.Errorf("too many redirects for %q", s.start)
+}
+
+// scan streams the dump once, collecting target and the pages it redirects to. The scan stops as soon as the article is
+// found.
+func (d *Dump) scan(s *dumpScan, target string) error {
+	if s.scanned == nil {
+		s.scanned = map[string]bool{}
+	}
+	s.scanned[target] = true
+
+	f, err := os.Open(d.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(d.path, ".bz2") {
+		r = bzip2.NewReader(f)
+	}
+
+	wanted := map[string]bool{target: true}
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read dump: %s", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "mediawiki":
+			for _, a := range start.Attr {
+				if a.Name.Local == "lang" {
+					s.siteLang = a.Value
+				}
+			}
+			if s.lang != "" && s.siteLang != "" && s.lang != s.siteLang {
+				return fmt.Errorf("the dump %s is of the %s wikipedia, not %s", d.path, s.siteLang, s.lang)
+			}
+		case "siteinfo":
+			var info dumpSiteInfo
+			if err := dec.DecodeElement(&info, &start); err != nil {
+				return fmt.Errorf("failed to read dump: %s", err)
+			}
+			if u, err := url.Parse(info.Base); err == nil {
+				s.wiki = wiki{u.Scheme, u.Host}
+			}
+		case "page":
+			var p dumpPage
+			if err := dec.DecodeElement(&p, &start); err != nil {
+				return fmt.Errorf("failed to read dump: %s", err)
+			}
+
+			title := dumpTitle(p.Title)
+			if !wanted[title] {
+				continue
+			}
+
+			s.pages[title] = &p
+			if p.Redirect != nil {
+				// keep scanning for the target of the redirect
+				wanted[dumpTitle(p.Redirect.Title)] = true
+				continue
+			}
+
+			if page, _, _ := s.resolve(); page != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// dumpTitle normalizes title for comparison with the titles of a dump, which start with an upper case letter
+func dumpTitle(title string) string {
+	return upperFirst(NormalizeTitle(title))
+}
@@ -0,0 +1,72 @@
+package wikipedia
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDumpFetch(t *testing.T) {
+	for _, file := range []string{"testwiki.xml", "testwiki.xml.bz2"} {
+		d := NewDump(baseDataPath + "dumps/" + file)
+
+		tests := map[string]struct {
+			title string
+			err   string
+		}{
+			"title":        {title: "Fire"},
+			"lower_case":   {title: "fire"},
+			"redirect":     {title: "Fires"},
+			"backwards":    {title: "Blaze"},
+			"prefix":       {title: "en:Fire"},
+			"prefix_other": {title: "de:Fire", err: "not de"},
+			"missing":      {title: "Smoke", err: "\"Smoke\" not found"},
+			"loop":         {title: "Loop", err: "too many redirects"},
+			"empty":        {title: " ", err: "invalid article"},
+		}
+
+		for name, tc := range tests {
+			t.Run(file+"/"+name, func(t *testing.T) {
+				page, err := d.Fetch(tc.title)
+				if tc.err != "" {
+					if assert.Error(t, err) {
+						assert.Contains(t, err.Error(), tc.err)
+					}
+					return
+				}
+
+				assert.NoError(t, err)
+				assert.Equal(t, "Fire", page.Title)
+				assert.Equal(t, "https://en.wikipedia.org/wiki/Fire", page.URL)
+				assert.Equal(t, "en", page.Language)
+				assert.Equal(t, 20, page.Revision)
+
+				p := NewArticleParser(WithLinkMode(LinkInline), WithFootnotes(true))
+				a, err := p.ParsePage(page)
+				assert.NoError(t, err)
+				assert.Equal(t, "# Fire\n\n**Fire** is the rapid oxidation of a material in a [hearth](https://en.wikipedia.org/wiki/Hearth).[^1]\n\n"+
+					"## Uses\n\n- Cooking\n- Heating\n\n[^1]: [Fire](https://example.com/fire)\n\n", p.Render(a))
+			})
+		}
+	}
+}
+
+func TestDumpScanFollowsRedirects(t *testing.T) {
+	d := NewDump(baseDataPath + "dumps/testwiki.xml")
+
+	// Fires redirects to Fire, which comes later in the dump, so a single pass is enough
+	s := &dumpScan{start: "Fires", pages: map[string]*dumpPage{}}
+	assert.NoError(t, d.scan(s, "Fires"))
+	page, _, err := s.resolve()
+	assert.NoError(t, err)
+	if assert.NotNil(t, page) {
+		assert.Equal(t, "Fire", page.Title)
+	}
+
+	// Blaze redirects to Fires, which precedes it
+	s = &dumpScan{start: "Blaze", pages: map[string]*dumpPage{}}
+	assert.NoError(t, d.scan(s, "Blaze"))
+	page, target, err := s.resolve()
+	assert.NoError(t, err)
+	assert.Nil(t, page)
+	assert.Equal(t, "Fires", target)
+}
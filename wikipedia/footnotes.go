@@ -0,0 +1,100 @@
+package wikipedia
+
+import (
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+	"strconv"
+	"strings"
+)
+
+// WithFootnotes enables rendering of citations as markdown footnotes. Each citation marker is replaced by a footnote
+// reference ([^1]) and the cited references are listed as footnote definitions at the end of the article.
+func WithFootnotes(enabled bool) Option {
+	return func(p *ArticleParser) {
+		p.footnotes = enabled
+	}
+}
+
+// newFootnoteConverter returns a rule which replaces citation markers with footnote references. Footnotes are numbered
+// in order of their first appearance.
+func newFootnoteConverter(enabled bool, st *parseState) md.Rule {
+	return md.Rule{
+		Filter: []string{"sup"},
+		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+			if !enabled || !selec.HasClass("reference") {
+				return md.String(content)
+			}
+
+			href := selec.Find("a").AttrOr("href", "")
+			if !strings.HasPrefix(href, "#") || len(href) == 1 {
+				return md.String(content)
+			}
+
+			return md.String("[^" + strconv.Itoa(st.footnote(href[1:])) + "]")
+		}}
+}
+
+// footnote returns the number of the footnote for the reference with the given id
+func (st *parseState) footnote(id string) int {
+	if st.footnoteIndex == nil {
+		st.footnoteIndex = map[string]int{}
+	}
+
+	if n, ok := st.footnoteIndex[id]; ok {
+		return n
+	}
+
+	st.footnotes = append(st.footnotes, id)
+	st.footnoteIndex[id] = len(st.footnotes)
+
+	return len(st.footnotes)
+}
+
+// missingFootnote is the text of footnotes whose reference can't be found or is empty
+const missingFootnote = "Reference not found."
+
+// footnoteTexts converts the text of all referenced footnotes using the matching entries of the articles reference
+// lists. References which can't be found get a placeholder text to keep the footnote references valid.
+func footnoteTexts(doc *goquery.Document, conv *md.Converter, st *parseState) ([]string, error) {
+	if len(st.footnotes) == 0 {
+		return nil, nil
+	}
+
+	refs := map[string]*goquery.Selection{}
+	doc.Find("ol.references > li, ol.mw-references > li").Each(func(_ int, li *goquery.Selection) {
+		if id, ok := li.Attr("id"); ok {
+			refs[id] = li
+		}
+	})
+
+	var texts []string
+	// converting a reference may add further footnotes, so the length is re-evaluated on every iteration
+	for i := 0; i < len(st.footnotes); i++ {
+		var text string
+		if li, ok := refs[st.footnotes[i]]; ok {
+			refText := li.Find("span.reference-text, span.mw-reference-text").First()
+			if refText.Length() == 0 {
+				refText = li.Clone()
+				refText.Find("span.mw-cite-backlink").Remove()
+			}
+
+			h, err := refText.Html()
+			if err != nil {
+				return nil, err
+			}
+
+			text, err = conv.ConvertString("<p>" + h + "</p>")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		text = strings.Join(strings.Fields(text), " ")
+		if text == "" {
+			text = missingFootnote
+		}
+		texts = append(texts, text)
+	}
+
+	return texts, nil
+}
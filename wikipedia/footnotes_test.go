@@ -0,0 +1,54 @@
+package wikipedia
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+const testFootnotes = "<div class=\"mw-parser-output\">" +
+	"<p>First<sup id=\"cite_ref-b\" class=\"reference\"><a href=\"#cite_note-b\">[2]</a></sup> " +
+	"second<sup id=\"cite_ref-a\" class=\"reference\"><a href=\"#cite_note-a\">[1]</a></sup> " +
+	"again<sup class=\"reference\"><a href=\"#cite_note-b\">[2]</a></sup> " +
+	"missing<sup class=\"reference\"><a href=\"#cite_note-x\">[3]</a></sup></p>" +
+	"<h2>Einzelnachweise</h2>" +
+	"<ol class=\"references\">" +
+	"<li id=\"cite_note-a\"><span class=\"mw-cite-backlink\"><a href=\"#cite_ref-a\">↑</a></span> <span class=\"reference-text\">Source <i>A</i></span></li>" +
+	"<li id=\"cite_note-b\"><span class=\"mw-cite-backlink\"><a href=\"#cite_ref-b\">↑</a></span> <span class=\"reference-text\"><a href=\"https://example.com\">Source B</a></span></li>" +
+	"</ol></div>"
+
+func TestFootnotes(t *testing.T) {
+	tests := map[string]struct {
+		opts []Option
+		// in defaults to testFootnotes
+		in  string
+		exp string
+	}{
+		"disabled": {opts: []Option{}, exp: "First second again missing\n\n"},
+		"enabled": {opts: []Option{WithFootnotes(true)},
+			exp: "First[^1] second[^2] again[^1] missing[^3]\n\n[^1]: Source B\n[^2]: Source _A_\n[^3]: Reference not found.\n\n"},
+		"enabled_with_links": {opts: []Option{WithFootnotes(true), WithLinkMode(LinkReference)},
+			exp: "First[^1] second[^2] again[^1] missing[^3]\n\n[^1]: [Source B][1]\n[^2]: Source _A_\n[^3]: Reference not found.\n\n" +
+				"[1]: https://example.com\n\n"},
+		"empty_reference": {opts: []Option{WithFootnotes(true)},
+			in: "<div class=\"mw-parser-output\"><p>Empty<sup class=\"reference\"><a href=\"#cite_note-e\">[1]</a></sup></p>" +
+				"<ol class=\"references\"><li id=\"cite_note-e\"><span class=\"reference-text\"></span></li></ol></div>",
+			exp: "Empty[^1]\n\n[^1]: Reference not found.\n\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			in := tc.in
+			if in == "" {
+				in = testFootnotes
+			}
+
+			p := NewArticleParser(tc.opts...)
+			act, err := p.Parse(io.NopCloser(strings.NewReader(in)))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package wikipedia
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const frontMatterDelimiter = "---\n"
+
+// yamlReservedRegex matches plain keys which YAML reads as booleans, null or numbers instead of strings
+var yamlReservedRegex = regexp.MustCompile(`^(?i:y|yes|n|no|true|false|on|off|null)$|^[-+]?[0-9]`)
+
+// frontMatter is an ordered set of YAML fields. Values are either strings, integers or nested frontMatter.
+type frontMatter []frontMatterField
+
+type frontMatterField struct {
+	key   string
+	value interface{}
+}
+
+// String renders the fields as YAML document enclosed in "---" delimiters
+func (fm frontMatter) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(frontMatterDelimiter)
+	fm.write(&sb, "")
+	sb.WriteString(frontMatterDelimiter)
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+func (fm frontMatter) write(sb *strings.Builder, indent string) {
+	for _, f := range fm {
+		sb.WriteString(indent + yamlKey(f.key) + ":")
+		switch v := f.value.(type) {
+		case frontMatter:
+			sb.WriteString("\n")
+			v.write(sb, indent+"  ")
+		case int:
+			sb.WriteString(" " + strconv.Itoa(v) + "\n")
+		case string:
+			sb.WriteString(" " + strconv.Quote(v) + "\n")
+		}
+	}
+}
+
+// yamlKey quotes key if it is not a plain identifier or would not be read as a string
+func yamlKey(key string) string {
+	if key == "" || yamlReservedRegex.MatchString(key) {
+		return strconv.Quote(key)
+	}
+
+	for _, r := range key {
+		if !(r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return strconv.Quote(key)
+		}
+	}
+
+	return key
+}
+
+// SplitFrontMatter splits a markdown document in to its front matter (including delimiters) and the remaining body.
+// If the document has no front matter, header is empty.
+func SplitFrontMatter(markdown string) (header, body string) {
+	if !strings.HasPrefix(markdown, frontMatterDelimiter) {
+		return "", markdown
+	}
+
+	end := strings.Index(markdown[len(frontMatterDelimiter):], "\n"+frontMatterDelimiter)
+	if end == -1 {
+		return "", markdown
+	}
+
+	end += len(frontMatterDelimiter) + len("\n"+frontMatterDelimiter)
+	body = strings.TrimLeft(markdown[end:], "\n")
+
+	return markdown[:end] + "\n", body
+}
@@ -0,0 +1,63 @@
+package wikipedia
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFrontMatterString(t *testing.T) {
+	fm := frontMatter{
+		{"title", "Hearth"},
+		{"revision", 42},
+		{"nested", frontMatter{{"key with space", "quoted \"value\""}}},
+	}
+
+	exp := "---\ntitle: \"Hearth\"\nrevision: 42\nnested:\n  \"key with space\": \"quoted \\\"value\\\"\"\n---\n\n"
+	assert.Equal(t, exp, fm.String())
+}
+
+func TestYAMLKey(t *testing.T) {
+	tests := map[string]struct {
+		key, exp string
+	}{
+		"plain":    {key: "title", exp: "title"},
+		"dash":     {key: "source-lang", exp: "source-lang"},
+		"space":    {key: "key with space", exp: "\"key with space\""},
+		"empty":    {key: "", exp: "\"\""},
+		"yes":      {key: "yes", exp: "\"yes\""},
+		"no":       {key: "No", exp: "\"No\""},
+		"on":       {key: "on", exp: "\"on\""},
+		"off":      {key: "OFF", exp: "\"OFF\""},
+		"true":     {key: "true", exp: "\"true\""},
+		"null":     {key: "null", exp: "\"null\""},
+		"tilde":    {key: "~", exp: "\"~\""},
+		"year":     {key: "1984", exp: "\"1984\""},
+		"negative": {key: "-1", exp: "\"-1\""},
+		"hex":      {key: "0x1F", exp: "\"0x1F\""},
+		"word":     {key: "nothing", exp: "nothing"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, yamlKey(tc.key))
+		})
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := map[string]struct {
+		in, header, body string
+	}{
+		"none":         {in: "# Title\n\ntext\n\n", header: "", body: "# Title\n\ntext\n\n"},
+		"front_matter": {in: "---\na: \"b\"\n---\n\n# Title\n\n", header: "---\na: \"b\"\n---\n\n", body: "# Title\n\n"},
+		"unterminated": {in: "---\na: \"b\"\n# Title\n\n", header: "", body: "---\na: \"b\"\n# Title\n\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			header, body := SplitFrontMatter(tc.in)
+			assert.Equal(t, tc.header, header)
+			assert.Equal(t, tc.body, body)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package wikipedia
+
+import (
+	"fmt"
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// UserAgent is sent with all requests to Wikimedia servers, which reject requests without a descriptive user agent.
+const UserAgent = "w2d (https://github.com/IljaN/w2d)"
+
+// imageSelector matches the thumbnails (legacy parser) and figures (Parsoid) in the article body
+const imageSelector = "div.thumb,figure"
+
+// WithImages enables rendering of thumbnails and figures as markdown images with their caption as alt-text
+func WithImages(enabled bool) Option {
+	return func(p *ArticleParser) {
+		p.images = enabled
+	}
+}
+
+// newImageConverter returns a rule which renders a thumbnail or figure as ![caption](absolute-url)
+func newImageConverter(st *parseState) md.Rule {
+	return md.Rule{
+		Filter: []string{"div", "figure"},
+		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+			if !selec.Is(imageSelector) {
+				return nil
+			}
+
+			img := selec.Find("img").First()
+			src := img.AttrOr("src", "")
+			if src == "" {
+				return md.String("")
+			}
+
+			caption := selec.Find("div.thumbcaption, figcaption").First().Clone()
+			caption.Find("div.magnify, style, sup.reference").Remove()
+			text := strings.Join(strings.Fields(caption.Text()), " ")
+			if text == "" {
+				text = img.AttrOr("alt", "")
+			}
+
+			text = strings.NewReplacer("[", `\[`, "]", `\]`).Replace(text)
+			// parentheses are escaped to keep the url parseable in markdown
+			target := strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(st.resolveURL(src))
+
+			return md.String("\n\n![" + text + "](" + target + ")\n\n")
+		}}
+}
+
+var imageRegex = regexp.MustCompile(`!\[((?:[^\]\\]|\\.)*)\]\((https?://[^)\s]+)\)`)
+
+// DownloadImages downloads all remote images referenced in markdown in to dir and rewrites the image links to the
+// local files. The links are relative to base, the directory the markdown is stored in. If base is empty, the links use
+// dir as given. Images referenced multiple times are only downloaded once.
+func DownloadImages(markdown, dir, base string, client *http.Client) (string, error) {
+	matches := imageRegex.FindAllStringSubmatch(markdown, -1)
+	if len(matches) == 0 {
+		return markdown, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	local := map[string]string{}
+	used := map[string]bool{}
+	for _, m := range matches {
+		src := m[2]
+		if _, ok := local[src]; ok {
+			continue
+		}
+
+		name := uniqueName(imageFileName(src), used)
+		if err := downloadFile(client, src, filepath.Join(dir, name)); err != nil {
+			return "", fmt.Errorf("failed to download image %s: %s", src, err)
+		}
+
+		link := filepath.Join(dir, name)
+		if base != "" {
+			if rel, err := relativePath(base, link); err == nil {
+				link = rel
+			}
+		}
+		local[src] = filepath.ToSlash(link)
+	}
+
+	return imageRegex.ReplaceAllStringFunc(markdown, func(s string) string {
+		m := imageRegex.FindStringSubmatch(s)
+		return "![" + m[1] + "](" + local[m[2]] + ")"
+	}), nil
+}
+
+// relativePath returns the path of target relative to the directory base
+func relativePath(base, target string) (string, error) {
+	base, err := filepath.Abs(base)
+	if err != nil {
+		return "", err
+	}
+
+	target, err = filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Rel(base, target)
+}
+
+// imageFileName derives a file name from the last path segment of an image url
+func imageFileName(src string) string {
+	name := "image"
+	if u, err := url.Parse(src); err == nil {
+		name = path.Base(u.Path)
+	}
+
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if name == "" || name == "." || name == "/" {
+		return "image"
+	}
+
+	return name
+}
+
+// uniqueName appends a counter to name if it was already used
+func uniqueName(name string, used map[string]bool) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	unique := name
+	for i := 1; used[unique]; i++ {
+		unique = base + "-" + strconv.Itoa(i) + ext
+	}
+	used[unique] = true
+
+	return unique
+}
+
+func downloadFile(client *http.Client, src, dst string) error {
+	req, err := http.NewRequest(http.MethodGet, src, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,93 @@
+package wikipedia
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testImages = "<div class=\"mw-parser-output\">" +
+	"<div class=\"thumb tright\"><div class=\"thumbinner\"><a href=\"/wiki/File:Hearth.jpg\" class=\"image\">" +
+	"<img alt=\"alt\" src=\"//upload.wikimedia.org/thumb/Hearth_(old).jpg/220px-Hearth_(old).jpg\"></a>" +
+	"<div class=\"thumbcaption\"><div class=\"magnify\"><a href=\"/wiki/File:Hearth.jpg\"></a></div>A <b>hearth</b> [1]</div></div></div>" +
+	"<p>paragraph</p>" +
+	"<figure typeof=\"mw:File/Thumb\"><a href=\"./File:Fire.png\"><img alt=\"Fire\" src=\"https://upload.wikimedia.org/Fire.png\"></a></figure>" +
+	"</div>"
+
+func TestImages(t *testing.T) {
+	tests := map[string]struct {
+		enabled bool
+		exp     string
+	}{
+		"disabled": {enabled: false, exp: "paragraph\n\n"},
+		"enabled": {enabled: true,
+			exp: "![A hearth \\[1\\]](https://upload.wikimedia.org/thumb/Hearth_%28old%29.jpg/220px-Hearth_%28old%29.jpg)\n\n" +
+				"paragraph\n\n![Fire](https://upload.wikimedia.org/Fire.png)\n\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := NewArticleParser(WithImages(tc.enabled))
+			act, err := p.Parse(io.NopCloser(strings.NewReader(testImages)))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
+
+func TestDownloadImages(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, UserAgent, r.Header.Get("User-Agent"))
+		if r.URL.Path == "/missing.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("data:" + r.URL.Path))
+	}))
+	defer srv.Close()
+
+	dir := filepath.Join(t.TempDir(), "images")
+	in := "# Title\n\n![one](" + srv.URL + "/a/Fire.png)\n\ntext\n\n![two](" + srv.URL + "/b/Fire.png)\n\n" +
+		"![again](" + srv.URL + "/a/Fire.png)\n\n![local](images/x.png)\n\n"
+
+	out, err := DownloadImages(in, dir, "", srv.Client())
+	assert.NoError(t, err)
+
+	local := filepath.ToSlash(dir)
+	exp := "# Title\n\n![one](" + local + "/Fire.png)\n\ntext\n\n![two](" + local + "/Fire-1.png)\n\n" +
+		"![again](" + local + "/Fire.png)\n\n![local](images/x.png)\n\n"
+	assert.Equal(t, exp, out)
+	assert.Equal(t, 2, requests)
+
+	// links are relative to the directory of the markdown file
+	tests := map[string]struct {
+		base, exp string
+	}{
+		"sibling": {base: filepath.Join(filepath.Dir(dir), "articles"), exp: "../images/Fire.png"},
+		"parent":  {base: filepath.Dir(dir), exp: "images/Fire.png"},
+		"same":    {base: dir, exp: "Fire.png"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out, err := DownloadImages("![one]("+srv.URL+"/a/Fire.png)", dir, tc.base, srv.Client())
+			assert.NoError(t, err)
+			assert.Equal(t, "![one]("+tc.exp+")", out)
+		})
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "Fire-1.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, "data:/b/Fire.png", string(b))
+
+	_, err = DownloadImages("![missing]("+srv.URL+"/missing.png)", dir, "", srv.Client())
+	assert.Error(t, err)
+}
@@ -0,0 +1,135 @@
+package wikipedia
+
+import (
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"strings"
+)
+
+// InfoboxMode controls if and how the facts of an articles infobox are rendered
+type InfoboxMode int
+
+const (
+	// InfoboxOmit drops the infobox, this is the default
+	InfoboxOmit InfoboxMode = iota
+	// InfoboxSummary renders the infobox as a list of facts below the title
+	InfoboxSummary
+	// InfoboxFrontMatter renders the infobox as YAML front matter at the top of the document
+	InfoboxFrontMatter
+)
+
+var infoboxModeNames = map[InfoboxMode]string{
+	InfoboxOmit:        "omit",
+	InfoboxSummary:     "summary",
+	InfoboxFrontMatter: "front-matter",
+}
+
+func (m InfoboxMode) String() string {
+	return infoboxModeNames[m]
+}
+
+// UnmarshalText parses the mode from its name, which allows using InfoboxMode directly as cli argument.
+func (m *InfoboxMode) UnmarshalText(text []byte) error {
+	for mode, name := range infoboxModeNames {
+		if name == string(text) {
+			*m = mode
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid infobox mode: %s (expected omit, summary or front-matter)", text)
+}
+
+// WithInfobox sets how the infobox of an article is rendered. See InfoboxMode.
+func WithInfobox(mode InfoboxMode) Option {
+	return func(p *ArticleParser) {
+		p.infobox = mode
+	}
+}
+
+// InfoboxField is a single label/value row of an infobox
+type InfoboxField struct {
+	Label, Value string
+}
+
+// Infobox holds the rows of an infobox in order of their appearance
+type Infobox []InfoboxField
+
+// parseInfobox extracts all label/value rows of the first infobox in the article body as plain text. Rows which do not
+// consist of exactly two cells (headings, images, nested tables) are skipped. Values of repeated labels are joined.
+func parseInfobox(doc *goquery.Document) Infobox {
+	var fields Infobox
+	index := map[string]int{}
+
+	table := doc.Find("div.mw-parser-output table.infobox").First()
+	table.Find("tr").Each(func(_ int, tr *goquery.Selection) {
+		if !tr.Closest("table").IsSelection(table) {
+			return
+		}
+
+		cells := tr.ChildrenFiltered("th,td")
+		if cells.Length() != 2 {
+			return
+		}
+
+		label := strings.TrimSuffix(cleanInfoboxText(cells.First().Text()), ":")
+
+		cell := cells.Last().Clone()
+		cell.Find("hr,style,sup.reference").Remove()
+		cell.Find("br").ReplaceWithHtml("\n")
+		value := cleanInfoboxText(cell.Text())
+
+		if label == "" || value == "" {
+			return
+		}
+
+		if i, ok := index[label]; ok {
+			fields[i].Value += ", " + value
+			return
+		}
+
+		index[label] = len(fields)
+		fields = append(fields, InfoboxField{label, value})
+	})
+
+	return fields
+}
+
+// cleanInfoboxText collapses the (multi-line) text of an infobox cell in to a single line
+func cleanInfoboxText(s string) string {
+	s = strings.ReplaceAll(s, "\u00ad", "") // soft hyphen
+	lines := strings.Split(s, "\n")
+	parts := make([]string, 0, len(lines))
+	for _, l := range lines {
+		l = strings.Join(strings.Fields(l), " ")
+		if l != "" {
+			parts = append(parts, l)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// summary renders the fields as markdown list with the labels in bold
+func (f Infobox) summary() string {
+	if len(f) == 0 {
+		return ""
+	}
+
+	sb := strings.Builder{}
+	for _, field := range f {
+		sb.WriteString("- **" + field.Label + ":** " + field.Value + "\n")
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+func (f Infobox) frontMatter() frontMatter {
+	fm := make(frontMatter, 0, len(f))
+	for _, field := range f {
+		fm = append(fm, frontMatterField{field.Label, field.Value})
+	}
+
+	return fm
+}
@@ -0,0 +1,49 @@
+package wikipedia
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+const testInfobox = "<h1 id=\"firstHeading\">Title</h1><div class=\"mw-parser-output\">" +
+	"<table class=\"infobox\"><tr><th colspan=\"2\">Heading</th></tr>" +
+	"<tr><th>Capital:</th><td><a href=\"/wiki/Kyiv\">Kyiv</a><sup class=\"reference\">[1]</sup></td></tr>" +
+	"<tr><td>Head of state</td><td>President<br>Someone</td></tr>" +
+	"<tr><td>Capital</td><td>Lviv</td></tr>" +
+	"<tr><td>Empty</td><td></td></tr>" +
+	"</table><p>paragraph</p></div>"
+
+func TestInfobox(t *testing.T) {
+	tests := map[string]struct {
+		in   string
+		mode InfoboxMode
+		exp  string
+	}{
+		"omit": {in: testInfobox, mode: InfoboxOmit, exp: "# Title\n\nparagraph\n\n"},
+		"summary": {in: testInfobox, mode: InfoboxSummary,
+			exp: "# Title\n\n- **Capital:** Kyiv, Lviv\n- **Head of state:** President, Someone\n\nparagraph\n\n"},
+		"front_matter": {in: testInfobox, mode: InfoboxFrontMatter,
+			exp: "---\ninfobox:\n  Capital: \"Kyiv, Lviv\"\n  \"Head of state\": \"President, Someone\"\n---\n\n# Title\n\nparagraph\n\n"},
+		"no_infobox_summary":      {in: "<div class=\"mw-parser-output\"><p>paragraph</p></div>", mode: InfoboxSummary, exp: "paragraph\n\n"},
+		"no_infobox_front_matter": {in: "<div class=\"mw-parser-output\"><p>paragraph</p></div>", mode: InfoboxFrontMatter, exp: "paragraph\n\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := NewArticleParser(WithInfobox(tc.mode))
+			act, err := p.Parse(io.NopCloser(strings.NewReader(tc.in)))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
+
+func TestInfoboxModeUnmarshalText(t *testing.T) {
+	var m InfoboxMode
+	assert.NoError(t, m.UnmarshalText([]byte("front-matter")))
+	assert.Equal(t, InfoboxFrontMatter, m)
+	assert.Error(t, m.UnmarshalText([]byte("invalid")))
+}
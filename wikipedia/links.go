@@ -0,0 +1,188 @@
+package wikipedia
+
+import (
+	"fmt"
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LinkMode controls how hyperlinks are rendered
+type LinkMode int
+
+const (
+	// LinkStrip replaces links with their text, this is the default
+	LinkStrip LinkMode = iota
+	// LinkInline renders links as inline markdown links: [text](url)
+	LinkInline
+	// LinkReference renders links as reference-style markdown links: [text][1] with all targets listed at the end
+	LinkReference
+)
+
+var linkModeNames = map[LinkMode]string{
+	LinkStrip:     "strip",
+	LinkInline:    "inline",
+	LinkReference: "reference",
+}
+
+func (m LinkMode) String() string {
+	return linkModeNames[m]
+}
+
+// UnmarshalText parses the mode from its name, which allows using LinkMode directly as cli argument.
+func (m *LinkMode) UnmarshalText(text []byte) error {
+	for mode, name := range linkModeNames {
+		if name == string(text) {
+			*m = mode
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid link mode: %s (expected strip, inline or reference)", text)
+}
+
+// WithLinkMode sets how hyperlinks are rendered. See LinkMode.
+func WithLinkMode(mode LinkMode) Option {
+	return func(p *ArticleParser) {
+		p.links = mode
+	}
+}
+
+// WithLinkRewriter sets a function which replaces the (absolute) target of every rendered link, e.g. to point links at
+// local copies of the linked articles.
+func WithLinkRewriter(rewrite func(target string) string) Option {
+	return func(p *ArticleParser) {
+		p.rewriteLink = rewrite
+	}
+}
+
+// WithBaseURL sets the url of the article which is used to resolve relative links. By default, the canonical url
+// from the articles html is used.
+func WithBaseURL(u *url.URL) Option {
+	return func(p *ArticleParser) {
+		p.baseURL = u
+	}
+}
+
+// canonicalURL returns the url from the <link rel="canonical"> tag of the document or nil if there is none
+func canonicalURL(doc *goquery.Document) *url.URL {
+	href := doc.Find("link[rel=canonical]").AttrOr("href", "")
+	if href == "" {
+		return nil
+	}
+
+	u, err := url.Parse(href)
+	if err != nil || !u.IsAbs() {
+		return nil
+	}
+
+	return u
+}
+
+// newLinkConverter returns a rule which renders links according to mode. Anchors (e.g. citations), edit-links and links
+// to non-existing articles are always reduced to their text. Links to other articles are collected in st.
+func newLinkConverter(mode LinkMode, rewrite func(target string) string, st *parseState) md.Rule {
+	return md.Rule{
+		Filter: []string{"a"},
+		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+			href, ok := selec.Attr("href")
+			if ok && strings.HasPrefix(href, "#") {
+				return md.String("")
+			}
+
+			if selec.HasClass("mw-editsection-visualeditor") || selec.ParentsFiltered(".mw-editsection").Length() > 0 {
+				return md.String("")
+			}
+
+			text := selec.Text()
+			if ok && !selec.HasClass("new") {
+				st.addArticleLink(st.resolveURL(href))
+			}
+
+			if mode == LinkStrip || !ok || selec.HasClass("new") || strings.TrimSpace(text) == "" {
+				return md.String(text)
+			}
+
+			// whitespace inside the link is moved outside the brackets
+			trimmed := strings.TrimSpace(text)
+			leading := text[:strings.Index(text, trimmed)]
+			trailing := text[len(leading)+len(trimmed):]
+			trimmed = linkTextEscaper.Replace(trimmed)
+
+			target := st.resolveURL(href)
+			if rewrite != nil {
+				target = rewrite(target)
+			}
+
+			if mode == LinkReference {
+				return md.String(leading + "[" + trimmed + "][" + strconv.Itoa(st.linkRef(target)) + "]" + trailing)
+			}
+
+			return md.String(leading + "[" + trimmed + "](" + target + ")" + trailing)
+		}}
+}
+
+// resolveURL returns href as absolute url relative to the article. Protocol-relative urls default to https.
+func (st *parseState) resolveURL(href string) string {
+	u, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+
+	if st.baseURL != nil {
+		return st.baseURL.ResolveReference(u).String()
+	}
+
+	if u.Scheme == "" && u.Host != "" {
+		u.Scheme = "https"
+	}
+
+	return u.String()
+}
+
+// linkRef returns the number of the reference-style link to target, starting at 1
+func (st *parseState) linkRef(target string) int {
+	if st.linkRefIndex == nil {
+		st.linkRefIndex = map[string]int{}
+	}
+
+	if n, ok := st.linkRefIndex[target]; ok {
+		return n
+	}
+
+	st.linkRefs = append(st.linkRefs, target)
+	st.linkRefIndex[target] = len(st.linkRefs)
+
+	return len(st.linkRefs)
+}
+
+// namespaceRegex matches titles with a namespace prefix like File:Hearth.jpg. Titles of articles may contain a colon as
+// well, but it is followed by a space then (Star_Wars:_Episode_IV).
+var namespaceRegex = regexp.MustCompile(`^[^:_/]+:[^_]`)
+
+// addArticleLink records target if it is an article on the same wiki as the parsed article
+func (st *parseState) addArticleLink(target string) {
+	u, err := url.Parse(target)
+	if err != nil || st.baseURL == nil || u.Host != st.baseURL.Host || !strings.HasPrefix(u.EscapedPath(), "/wiki/") {
+		return
+	}
+
+	title := strings.TrimPrefix(u.EscapedPath(), "/wiki/")
+	if title == "" || namespaceRegex.MatchString(title) || u.RawQuery != "" {
+		return
+	}
+
+	u.Fragment = ""
+	target = u.String()
+	if st.articleLinkSeen == nil {
+		st.articleLinkSeen = map[string]bool{}
+	}
+
+	if !st.articleLinkSeen[target] {
+		st.articleLinkSeen[target] = true
+		st.articleLinks = append(st.articleLinks, target)
+	}
+}
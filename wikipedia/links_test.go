@@ -0,0 +1,109 @@
+package wikipedia
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testLinks = "<html><head><link rel=\"canonical\" href=\"https://en.wikipedia.org/wiki/Hearth\"/></head><body>" +
+	"<div class=\"mw-parser-output\"><p>A <a href=\"/wiki/Fire\">fire</a> and <a href=\"https://example.com/x\">ext</a>" +
+	"<sup class=\"reference\"><a href=\"#cite_note-1\">[1]</a></sup> " +
+	"<a href=\"/w/index.php?title=Missing&amp;action=edit&amp;redlink=1\" class=\"new\">missing</a> " +
+	"<a href=\"/wiki/Fire\"> again </a></p>" +
+	"<h2>Section<span class=\"mw-editsection\"><a href=\"/w/index.php?title=Hearth&amp;action=edit&amp;section=1\">edit</a></span></h2>" +
+	"<p><a href=\"//commons.wikimedia.org/wiki/Hearth\">commons</a></p></div></body></html>"
+
+func TestLinks(t *testing.T) {
+	tests := map[string]struct {
+		mode LinkMode
+		exp  string
+	}{
+		"strip": {mode: LinkStrip,
+			exp: "A fire and ext missing again \n\n## Section\n\ncommons\n\n"},
+		"inline": {mode: LinkInline,
+			exp: "A [fire](https://en.wikipedia.org/wiki/Fire) and [ext](https://example.com/x) missing " +
+				"[again](https://en.wikipedia.org/wiki/Fire) \n\n## Section\n\n" +
+				"[commons](https://commons.wikimedia.org/wiki/Hearth)\n\n"},
+		"reference": {mode: LinkReference,
+			exp: "A [fire][1] and [ext][2] missing [again][1] \n\n## Section\n\n[commons][3]\n\n" +
+				"[1]: https://en.wikipedia.org/wiki/Fire\n[2]: https://example.com/x\n[3]: https://commons.wikimedia.org/wiki/Hearth\n\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := NewArticleParser(WithLinkMode(tc.mode))
+			act, err := p.Parse(io.NopCloser(strings.NewReader(testLinks)))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
+
+func TestLinkTextEscaped(t *testing.T) {
+	in := "<div class=\"mw-parser-output\"><p><a href=\"https://en.wikipedia.org/wiki/Array\">Array[0] and C:\\ path</a></p></div>"
+	tests := map[string]struct {
+		mode LinkMode
+		exp  string
+	}{
+		"inline":    {mode: LinkInline, exp: "[Array\\[0\\] and C:\\\\ path](https://en.wikipedia.org/wiki/Array)\n\n"},
+		"reference": {mode: LinkReference, exp: "[Array\\[0\\] and C:\\\\ path][1]\n\n[1]: https://en.wikipedia.org/wiki/Array\n\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := NewArticleParser(WithLinkMode(tc.mode))
+			act, err := p.Parse(io.NopCloser(strings.NewReader(in)))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
+
+func TestLinksBaseURL(t *testing.T) {
+	in := "<div class=\"mw-parser-output\"><p><a href=\"/wiki/Fire\">fire</a> <a href=\"./Smoke\">smoke</a></p></div>"
+
+	t.Run("without_base", func(t *testing.T) {
+		act, err := NewArticleParser(WithLinkMode(LinkInline)).Parse(io.NopCloser(strings.NewReader(in)))
+		assert.NoError(t, err)
+		assert.Equal(t, "[fire](/wiki/Fire) [smoke](./Smoke)\n\n", act)
+	})
+
+	t.Run("with_base", func(t *testing.T) {
+		base, _ := url.Parse("https://de.wikipedia.org/wiki/Herd")
+		act, err := NewArticleParser(WithLinkMode(LinkInline), WithBaseURL(base)).Parse(io.NopCloser(strings.NewReader(in)))
+		assert.NoError(t, err)
+		assert.Equal(t, "[fire](https://de.wikipedia.org/wiki/Fire) [smoke](https://de.wikipedia.org/wiki/Smoke)\n\n", act)
+	})
+}
+
+func TestArticleLinks(t *testing.T) {
+	in := "<html><head><link rel=\"canonical\" href=\"https://en.wikipedia.org/wiki/Hearth\"/></head><body>" +
+		"<div class=\"mw-parser-output\"><p><a href=\"/wiki/Fire#History\">fire</a> <a href=\"/wiki/Fire\">fire</a> " +
+		"<a href=\"/wiki/File:Hearth.jpg\">file</a> <a href=\"/wiki/Star_Wars:_Episode_IV\">film</a> " +
+		"<a href=\"https://de.wikipedia.org/wiki/Herd\">herd</a> <a href=\"/w/index.php?title=Missing\" class=\"new\">missing</a></p></div></body></html>"
+
+	for _, mode := range []LinkMode{LinkStrip, LinkInline} {
+		t.Run(mode.String(), func(t *testing.T) {
+			a, err := NewArticleParser(WithLinkMode(mode)).ParseArticle(io.NopCloser(strings.NewReader(in)))
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"https://en.wikipedia.org/wiki/Fire", "https://en.wikipedia.org/wiki/Star_Wars:_Episode_IV"}, a.Links)
+		})
+	}
+}
+
+func TestLinkRewriter(t *testing.T) {
+	rewrite := func(target string) string {
+		return strings.Replace(target, "https://en.wikipedia.org/wiki/", "local/", 1)
+	}
+
+	p := NewArticleParser(WithLinkMode(LinkReference), WithLinkRewriter(rewrite))
+	act, err := p.Parse(io.NopCloser(strings.NewReader(testLinks)))
+	assert.NoError(t, err)
+	assert.Equal(t, "A [fire][1] and [ext][2] missing [again][1] \n\n## Section\n\n[commons][3]\n\n"+
+		"[1]: local/Fire\n[2]: https://example.com/x\n[3]: https://commons.wikimedia.org/wiki/Hearth\n\n", act)
+}
@@ -0,0 +1,51 @@
+package wikipedia
+
+import (
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+	"strings"
+)
+
+// definitionIndent is used for definitions and their continuation lines, as in the definition lists of pandoc and
+// PHP Markdown Extra.
+const definitionIndent = "    "
+
+var (
+	definitionListConverter = md.Rule{
+		Filter: []string{"dl"},
+		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+			if strings.TrimSpace(content) == "" {
+				return md.String("")
+			}
+
+			return md.String("\n\n" + strings.TrimRight(content, "\n") + "\n\n")
+		}}
+
+	// definitionTermConverter renders the term in bold on its own line
+	definitionTermConverter = md.Rule{
+		Filter: []string{"dt"},
+		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+			content = strings.Join(strings.Fields(content), " ")
+			if content == "" {
+				return md.String("")
+			}
+
+			return md.String(opt.StrongDelimiter + content + opt.StrongDelimiter + "\n")
+		}}
+
+	// definitionConverter renders the definition as ":   definition" with all following lines indented
+	definitionConverter = md.Rule{
+		Filter: []string{"dd"},
+		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+			content = strings.Trim(content, "\n ")
+			if content == "" {
+				return md.String("")
+			}
+
+			content = multipleNewLinesRegex.ReplaceAllString(content, "\n\n")
+			content = strings.ReplaceAll(content, "\n", "\n"+definitionIndent)
+			content = strings.ReplaceAll(content, "\n"+definitionIndent+"\n", "\n\n")
+
+			return md.String(":" + definitionIndent[1:] + content + "\n")
+		}}
+)
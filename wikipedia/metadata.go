@@ -0,0 +1,127 @@
+package wikipedia
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// License of the text of all Wikipedia articles
+	License    = "CC BY-SA 4.0"
+	LicenseURL = "https://creativecommons.org/licenses/by-sa/4.0/"
+)
+
+// Metadata describes the source of an article
+type Metadata struct {
+	Title     string
+	URL       string
+	Language  string
+	Revision  int
+	Retrieved time.Time
+}
+
+// Translation describes how an article was translated
+type Translation struct {
+	SourceLang string
+	TargetLang string
+	Translator string
+}
+
+// WithFrontMatter enables YAML front matter containing the metadata of the article. If the infobox is rendered as
+// front matter as well, both share the same document.
+func WithFrontMatter(enabled bool) Option {
+	return func(p *ArticleParser) {
+		p.frontMatter = enabled
+	}
+}
+
+// WithTranslation records in the front matter that the article is going to be translated. If sourceLang is empty the
+// language of the article is used.
+func WithTranslation(sourceLang, targetLang, translator string) Option {
+	return func(p *ArticleParser) {
+		p.translation = &Translation{sourceLang, targetLang, translator}
+	}
+}
+
+var revisionRegex = regexp.MustCompile(`"wgRevisionId":\s*(\d+)`)
+
+// parseMetadata extracts the metadata from the html of an article page
+func parseMetadata(doc *goquery.Document, st *parseState, now time.Time) Metadata {
+	m := Metadata{
+		Title:     strings.TrimSpace(doc.Find("h1#firstHeading").Text()),
+		Language:  doc.Find("html").AttrOr("lang", ""),
+		Retrieved: now.UTC().Truncate(time.Second),
+	}
+
+	// html returned by the api is only the article body, which carries the language as well
+	if m.Language == "" {
+		m.Language = doc.Find("div.mw-parser-output").AttrOr("lang", "")
+	}
+
+	if st.baseURL != nil {
+		m.URL = st.baseURL.String()
+	}
+
+	doc.Find("script").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if match := revisionRegex.FindStringSubmatch(s.Text()); match != nil {
+			m.Revision, _ = strconv.Atoi(match[1])
+			return false
+		}
+		return true
+	})
+
+	return m
+}
+
+// merge overrides the fields of m with the non-empty fields of page
+func (m Metadata) merge(page *Page) Metadata {
+	if page.Title != "" {
+		m.Title = page.Title
+	}
+	if page.URL != "" {
+		m.URL = page.URL
+	}
+	if page.Language != "" {
+		m.Language = page.Language
+	}
+	if page.Revision != 0 {
+		m.Revision = page.Revision
+	}
+
+	return m
+}
+
+func (m Metadata) frontMatter() frontMatter {
+	fm := frontMatter{{"title", m.Title}}
+	if m.URL != "" {
+		fm = append(fm, frontMatterField{"source", m.URL})
+	}
+	if m.Language != "" {
+		fm = append(fm, frontMatterField{"language", m.Language})
+	}
+	if m.Revision != 0 {
+		fm = append(fm, frontMatterField{"revision_id", m.Revision})
+	}
+
+	return append(fm,
+		frontMatterField{"retrieved", m.Retrieved.Format(time.RFC3339)},
+		frontMatterField{"license", License},
+		frontMatterField{"license_url", LicenseURL},
+	)
+}
+
+func (t Translation) frontMatter(articleLang string) frontMatter {
+	src := t.SourceLang
+	if src == "" {
+		src = articleLang
+	}
+
+	return frontMatter{
+		{"source_language", src},
+		{"target_language", t.TargetLang},
+		{"translator", t.Translator},
+	}
+}
@@ -0,0 +1,52 @@
+package wikipedia
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMetadata = "<html lang=\"en\"><head>" +
+	"<link rel=\"canonical\" href=\"https://en.wikipedia.org/wiki/Hearth\">" +
+	"<script>RLCONF={\"wgPageName\":\"Hearth\",\"wgRevisionId\":1072590799};</script></head>" +
+	"<body><h1 id=\"firstHeading\">Hearth</h1><div class=\"mw-parser-output\">" +
+	"<table class=\"infobox\"><tr><td>Type</td><td>Fireplace</td></tr></table><p>paragraph</p></div></body></html>"
+
+func TestFrontMatterMetadata(t *testing.T) {
+	meta := "---\ntitle: \"Hearth\"\nsource: \"https://en.wikipedia.org/wiki/Hearth\"\nlanguage: \"en\"\n" +
+		"revision_id: 1072590799\nretrieved: \"2022-03-01T12:30:00Z\"\nlicense: \"CC BY-SA 4.0\"\n" +
+		"license_url: \"https://creativecommons.org/licenses/by-sa/4.0/\"\n"
+
+	tests := map[string]struct {
+		in   string
+		opts []Option
+		exp  string
+	}{
+		"metadata": {in: testMetadata, opts: []Option{WithFrontMatter(true)},
+			exp: meta + "---\n\n# Hearth\n\nparagraph\n\n"},
+		"translation": {in: testMetadata, opts: []Option{WithFrontMatter(true), WithTranslation("", "DE", "deepl")},
+			exp: meta + "source_language: \"en\"\ntarget_language: \"DE\"\ntranslator: \"deepl\"\n---\n\n# Hearth\n\nparagraph\n\n"},
+		"explicit_source_language": {in: testMetadata, opts: []Option{WithFrontMatter(true), WithTranslation("EN", "DE", "deepl")},
+			exp: meta + "source_language: \"EN\"\ntarget_language: \"DE\"\ntranslator: \"deepl\"\n---\n\n# Hearth\n\nparagraph\n\n"},
+		"infobox": {in: testMetadata, opts: []Option{WithFrontMatter(true), WithInfobox(InfoboxFrontMatter)},
+			exp: meta + "infobox:\n  Type: \"Fireplace\"\n---\n\n# Hearth\n\nparagraph\n\n"},
+		"translation_disabled": {in: testMetadata, opts: []Option{WithTranslation("", "DE", "deepl")},
+			exp: "# Hearth\n\nparagraph\n\n"},
+		"missing_metadata": {in: "<div class=\"mw-parser-output\"><p>paragraph</p></div>", opts: []Option{WithFrontMatter(true)},
+			exp: "---\ntitle: \"\"\nretrieved: \"2022-03-01T12:30:00Z\"\nlicense: \"CC BY-SA 4.0\"\n" +
+				"license_url: \"https://creativecommons.org/licenses/by-sa/4.0/\"\n---\n\nparagraph\n\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := NewArticleParser(tc.opts...)
+			p.now = func() time.Time { return time.Date(2022, 3, 1, 13, 30, 0, 500, time.FixedZone("CET", 3600)) }
+			act, err := p.Parse(io.NopCloser(strings.NewReader(tc.in)))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
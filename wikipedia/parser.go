@@ -5,37 +5,111 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"golang.org/x/net/html"
 	"io"
+	"net/url"
 	"regexp"
 	"strings"
+	"time"
+	"unicode"
 )
 
-func NewArticleParser() *ArticleParser {
-	return &ArticleParser{
-		md: md.NewConverter("", true, nil).
-			AddRules(linkRemover, editBoxRemover, newLineFixer).
-			ClearAfter().
-			After(afterHook),
+// Option configures optional behaviour of the ArticleParser
+type Option func(p *ArticleParser)
+
+func NewArticleParser(opts ...Option) *ArticleParser {
+	p := &ArticleParser{now: time.Now}
+	for _, opt := range opts {
+		opt(p)
 	}
+
+	return p
+}
+
+// parseState holds everything which is collected while converting a single article
+type parseState struct {
+	baseURL *url.URL
+	// targets of reference-style links in order of their first appearance
+	linkRefs     []string
+	linkRefIndex map[string]int
+	// ids of the cited references in order of their first appearance
+	footnotes     []string
+	footnoteIndex map[string]int
+	// urls of the linked articles in order of their first appearance
+	articleLinks    []string
+	articleLinkSeen map[string]bool
 }
 
+// newConverter returns the markdown converter for a single article. Rules which collect data across the whole article
+// write it to st.
+func (p *ArticleParser) newConverter(st *parseState) *md.Converter {
+	return md.NewConverter("", true, nil).
+		AddRules(whitespaceFixer, newLinkConverter(p.links, p.rewriteLink, st), newFootnoteConverter(p.footnotes, st), editBoxRemover,
+			newLineFixer, tableCellConverter, tableConverter,
+			definitionListConverter, definitionTermConverter, definitionConverter, newImageConverter(st)).
+		ClearAfter().
+		After(afterHook)
+}
+
+// Parse converts the html of an article page to markdown
 func (p *ArticleParser) Parse(html io.ReadCloser) (string, error) {
-	var sb = strings.Builder{}
-	var doc *goquery.Document
-	var err error
-	sb.Grow(256000)
+	a, err := p.ParseArticle(html)
+	if err != nil {
+		return "", err
+	}
 
+	return p.Render(a), nil
+}
+
+// ParseArticle parses the html of an article page in to an Article
+func (p *ArticleParser) ParseArticle(html io.ReadCloser) (*Article, error) {
 	defer html.Close()
 
-	doc, err = goquery.NewDocumentFromReader(html)
+	doc, err := goquery.NewDocumentFromReader(html)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	title := doc.Find("h1#firstHeading")
-	if title.Text() != "" {
-		sb.WriteString("# " + title.Text() + "\n\n")
+
+	return p.parseDocument(doc, nil)
+}
+
+// ParsePage parses a page returned by a Client in to an Article. The metadata of the page takes precedence over the
+// metadata found in its html.
+func (p *ArticleParser) ParsePage(page *Page) (*Article, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page.HTML))
+	if err != nil {
+		return nil, err
 	}
 
-	articleStart := doc.Find("div.mw-parser-output").ChildrenFiltered("h2,p,ul")
+	return p.parseDocument(doc, page)
+}
+
+func (p *ArticleParser) parseDocument(doc *goquery.Document, page *Page) (*Article, error) {
+	var err error
+
+	st := &parseState{baseURL: p.baseURL}
+	if st.baseURL == nil && page != nil && page.URL != "" {
+		st.baseURL, _ = url.Parse(page.URL)
+	}
+	if st.baseURL == nil {
+		st.baseURL = canonicalURL(doc)
+	}
+	conv := p.newConverter(st)
+
+	a := &Article{Metadata: parseMetadata(doc, st, p.now()), Translation: p.translation}
+	if page != nil {
+		a.Metadata = a.Metadata.merge(page)
+	}
+	if p.infobox != InfoboxOmit {
+		a.Infobox = parseInfobox(doc)
+	}
+
+	selector := contentSelector
+	if p.images {
+		selector += "," + imageSelector
+	}
+
+	// stack of the currently open sections, subsections are added to the last section of a lower level
+	var open []*Section
+	articleStart := p.filterSections(doc.Find("div.mw-parser-output").ChildrenFiltered(selector))
 	articleStart.EachWithBreak(func(i int, selection *goquery.Selection) bool {
 
 		if isEmptyHeading(i, articleStart.Nodes) {
@@ -49,29 +123,177 @@ func (p *ArticleParser) Parse(html io.ReadCloser) (string, error) {
 		}
 
 		var markdown = ""
-		markdown, err = p.md.ConvertString(h)
+		markdown, err = conv.ConvertString(h)
 		if err != nil {
 			return false
 		}
 
-		sb.WriteString(markdown)
+		n := selection.Nodes[0]
+		if level := headingLevel(n); level != 0 {
+			s := &Section{Heading: headingMarkdown(markdown, level), Level: level}
+			for len(open) > 0 && open[len(open)-1].Level >= level {
+				open = open[:len(open)-1]
+			}
+
+			if len(open) == 0 {
+				a.Sections = append(a.Sections, s)
+			} else {
+				parent := open[len(open)-1]
+				parent.Sections = append(parent.Sections, s)
+			}
+			open = append(open, s)
+
+			return true
+		}
+
+		b := Block{Kind: blockKind(n), Markdown: markdown}
+		if len(open) == 0 {
+			a.Lead = append(a.Lead, b)
+		} else {
+			s := open[len(open)-1]
+			s.Blocks = append(s.Blocks, b)
+		}
+
 		return true
 	})
 
-	return sb.String(), err
+	if err != nil {
+		return nil, err
+	}
+
+	a.Footnotes, err = footnoteTexts(doc, conv, st)
+	if err != nil {
+		return nil, err
+	}
+	a.LinkRefs = st.linkRefs
+	a.Links = st.articleLinks
+
+	return a, nil
+}
 
+// headingMarkdown returns the text of a converted heading without the leading hashes
+func headingMarkdown(markdown string, level int) string {
+	markdown = strings.TrimPrefix(strings.TrimSpace(markdown), strings.Repeat("#", level))
+	return strings.TrimSpace(markdown)
 }
 
-// isEmptyHeading returns true if the current and next node in nodes relative to curIdx is a heading
+// blockKind returns the kind of block a content node (see contentSelector and imageSelector) is converted to
+func blockKind(n *html.Node) BlockKind {
+	switch {
+	case n.Data == "ul" || n.Data == "ol":
+		return List
+	case n.Data == "dl":
+		return DefinitionList
+	case n.Data == "table":
+		return Table
+	case n.Data == "figure" || hasClass(n, "thumb"):
+		return Image
+	case n.Data == "blockquote" || isQuoteTemplate(n):
+		return Quote
+	}
+
+	return Paragraph
+}
+
+// contentSelector matches the direct children of the article body which are converted to markdown. Infoboxes are
+// excluded as they are styled as wikitables in some wikis. Newer MediaWiki versions wrap headings in div.mw-heading.
+// Quotations are usually wrapped in the divs of quote templates (see quoteTemplateClasses). The list of references is an
+// ol as well, but is not part of the article text.
+const contentSelector = "h2,h3,h4,h5,h6,div.mw-heading,p,ul,ol:not(.references),dl,blockquote,div.Vorlage_Zitat," +
+	"div.quotebox,table.wikitable:not(.infobox)"
+
+// quoteTemplateClasses are the classes of the divs quote templates wrap their blockquote in
+var quoteTemplateClasses = []string{"Vorlage_Zitat", "quotebox"}
+
+// isQuoteTemplate returns true if n is the div of a quote template
+func isQuoteTemplate(n *html.Node) bool {
+	if n.Data != "div" {
+		return false
+	}
+
+	for _, class := range quoteTemplateClasses {
+		if hasClass(n, class) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isEmptyHeading returns true if the node in nodes at curIdx is a heading which is not followed by any content before
+// the next heading of the same or a higher level (or the end of the article). Subheadings don't count as content, so a
+// section which only consists of empty subsections is empty as well.
 func isEmptyHeading(curIdx int, nodes []*html.Node) bool {
-	isLast, next := curIdx == len(nodes)-1, curIdx+1
+	level := headingLevel(nodes[curIdx])
+	if level == 0 {
+		return false
+	}
 
-	if !isLast && nodes[curIdx].Data == "h2" && nodes[next].Data == "h2" {
-		return true
+	for _, n := range nodes[curIdx+1:] {
+		nextLevel := headingLevel(n)
+		if nextLevel == 0 {
+			return false
+		}
+
+		if nextLevel <= level {
+			return true
+		}
 	}
 
-	if isLast && nodes[curIdx].Data == "h2" {
-		return true
+	return true
+}
+
+// headingLevel returns the level of a heading node (h1-h6 or a div.mw-heading wrapping one) or 0 if n is no heading
+func headingLevel(n *html.Node) int {
+	if n.Type != html.ElementNode {
+		return 0
+	}
+
+	if len(n.Data) == 2 && n.Data[0] == 'h' && n.Data[1] >= '1' && n.Data[1] <= '6' {
+		return int(n.Data[1] - '0')
+	}
+
+	if n.Data == "div" && hasClass(n, "mw-heading") {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if level := headingLevel(c); level != 0 {
+				return level
+			}
+		}
+	}
+
+	return 0
+}
+
+func isInlineNode(n *html.Node) bool {
+	return n.Type == html.ElementNode && md.IsInlineElement(n.Data)
+}
+
+var (
+	// paddedElements are padded with spaces by the converter if they are next to text. A space after them is only added
+	// if none of the unpaddedElements follows, which the converter expects to pad themselves.
+	paddedElements   = map[string]bool{"b": true, "strong": true, "i": true, "em": true, "code": true}
+	unpaddedElements = map[string]bool{"a": true, "del": true, "s": true, "strike": true}
+)
+
+// hasOuterSpace reports whether the text of n starts (leading) or ends with whitespace, which is kept outside of links
+func hasOuterSpace(n *html.Node, leading bool) bool {
+	text := md.CollectText(n)
+	if leading {
+		return strings.TrimLeftFunc(text, unicode.IsSpace) != text
+	}
+
+	return strings.TrimRightFunc(text, unicode.IsSpace) != text
+}
+
+func hasClass(n *html.Node, class string) bool {
+	for _, a := range n.Attr {
+		if a.Key == "class" {
+			for _, c := range strings.Fields(a.Val) {
+				if c == class {
+					return true
+				}
+			}
+		}
 	}
 
 	return false
@@ -88,7 +310,7 @@ func afterHook(markdown string) string {
 
 var (
 	newLineFixer = md.Rule{
-		Filter: []string{"h2", "p"},
+		Filter: []string{"h2", "h3", "h4", "h5", "h6", "p"},
 		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
 			if len(selec.Nodes) == 1 {
 				content = strings.ReplaceAll(content, "\n", "")
@@ -97,29 +319,36 @@ var (
 					return md.String(content)
 				}
 
-				if selec.Nodes[0].Data == "h2" {
-					content = "## " + content + "\n\n"
+				if level := headingLevel(selec.Nodes[0]); level != 0 {
+					content = strings.Repeat("#", level) + " " + content + "\n\n"
 					return md.String(content)
 				}
 			}
 			return nil
 		}}
 
-	linkRemover = md.Rule{
-		Filter: []string{"a"},
+	// whitespaceFixer keeps whitespace-only text between two inline elements, which the default rule drops, so that
+	// e.g. two adjacent links don't run in to each other. The space is left out if one of the elements renders it
+	// already.
+	whitespaceFixer = md.Rule{
+		Filter: []string{"#text"},
 		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
-			if sel, ok := selec.Attr("href"); ok && strings.HasPrefix(sel, "#") {
-				content = ""
-				return md.String(content)
+			n := selec.Nodes[0]
+			if strings.TrimSpace(n.Data) != "" || n.PrevSibling == nil || n.NextSibling == nil {
+				return nil
 			}
 
-			if selec.HasClass("mw-editsection-visualeditor") {
-				content = ""
-				return md.String(content)
+			if !isInlineNode(n.PrevSibling) || !isInlineNode(n.NextSibling) {
+				return nil
 			}
 
-			content = selec.Text()
-			return md.String(content)
+			prev, next := n.PrevSibling, n.NextSibling
+			if paddedElements[next.Data] || paddedElements[prev.Data] && !unpaddedElements[next.Data] ||
+				hasOuterSpace(prev, false) || hasOuterSpace(next, true) {
+				return md.String("")
+			}
+
+			return md.String(" ")
 		}}
 
 	editBoxRemover = md.Rule{
@@ -135,5 +364,19 @@ var (
 )
 
 type ArticleParser struct {
-	md *md.Converter
+	infobox InfoboxMode
+	links   LinkMode
+	// rewriteLink replaces the target of rendered links, if set
+	rewriteLink func(target string) string
+	footnotes   bool
+	images      bool
+	baseURL     *url.URL
+
+	sections         []string
+	excludedSections []string
+	leadOnly         bool
+
+	frontMatter bool
+	translation *Translation
+	now         func() time.Time
 }
@@ -22,8 +22,40 @@ func TestParser(t *testing.T) {
 		"no_empty_subheading_end":     {in: "<div class=\"mw-parser-output\"><h2>Subheading1</h2><p>paragraph</p><h2>Subheading2</h2><h2>Subheading3</h2></div>", exp: "## Subheading1\n\nparagraph\n\n"},
 		"no_empty_subheading_between": {in: "<div class=\"mw-parser-output\"><h2>Subheading1</h2><p>paragraph1</p><h2>Subheading2</h2><h2>Subheading3</h2><p>paragraph3</p></div>", exp: "## Subheading1\n\nparagraph1\n\n## Subheading3\n\nparagraph3\n\n"},
 
+		"subheading_levels":          {in: "<div class=\"mw-parser-output\"><h2>H2</h2><h3>H3</h3><p>p3</p><h4>H4</h4><p>p4</p><h5>H5</h5><p>p5</p><h6>H6</h6><p>p6</p></div>", exp: "## H2\n\n### H3\n\np3\n\n#### H4\n\np4\n\n##### H5\n\np5\n\n###### H6\n\np6\n\n"},
+		"subheading_mw_heading":      {in: "<div class=\"mw-parser-output\"><div class=\"mw-heading mw-heading2\"><h2 id=\"A\">A</h2><span class=\"mw-editsection\">edit</span></div><p>p1</p><div class=\"mw-heading mw-heading3\"><h3>B</h3></div><p>p2</p></div>", exp: "## A\n\np1\n\n### B\n\np2\n\n"},
+		"no_empty_subheading_nested": {in: "<div class=\"mw-parser-output\"><h2>A</h2><h3>A1</h3><h3>A2</h3><h2>B</h2><h3>B1</h3><p>p</p><h3>B2</h3></div>", exp: "## B\n\n### B1\n\np\n\n"},
+		"no_empty_subheading_level":  {in: "<div class=\"mw-parser-output\"><h2>A</h2><h3>A1</h3><p>p1</p><h3>A2</h3><h2>B</h2><p>p2</p></div>", exp: "## A\n\n### A1\n\np1\n\n## B\n\np2\n\n"},
+		"no_empty_mw_heading":        {in: "<div class=\"mw-parser-output\"><div class=\"mw-heading mw-heading2\"><h2>A</h2></div><div class=\"mw-heading mw-heading2\"><h2>B</h2></div><p>p</p></div>", exp: "## B\n\np\n\n"},
+
 		"edit_box_removed": {in: "<div class=\"mw-parser-output\"><span class=\"mw-editsection\">editbox</span><<p>p1</p>/div>", exp: "p1\n\n"},
 		"link_transformer": {in: "<div class=\"mw-parser-output\"><p>paragraph <a href=\"https://example.com\">link</a> end</div>", exp: "paragraph link end\n\n"},
+
+		"space_between_links":   {in: "<div class=\"mw-parser-output\"><p><a href=\"/wiki/A\">fire</a> <a href=\"/wiki/B\">brick</a></p></div>", exp: "fire brick\n\n"},
+		"space_before_emphasis": {in: "<div class=\"mw-parser-output\"><p><a href=\"/wiki/A\">NGO</a> <i>Netzwerk</i> nach</p></div>", exp: "NGO _Netzwerk_ nach\n\n"},
+		"space_inside_link":     {in: "<div class=\"mw-parser-output\"><p><a href=\"/wiki/A\">missing</a> <a href=\"/wiki/B\"> again</a></p></div>", exp: "missing again\n\n"},
+		"space_after_bold_link": {in: "<div class=\"mw-parser-output\"><p><b>bold</b> <a href=\"/wiki/B\">link</a></p></div>", exp: "**bold** link\n\n"},
+
+		"list_ordered":          {in: "<div class=\"mw-parser-output\"><ol><li>one</li><li>two</li></ol></div>", exp: "1. one\n2. two\n\n"},
+		"list_nested":           {in: "<div class=\"mw-parser-output\"><ol><li>one<ul><li>a</li><li>b</li></ul></li><li>two</li></ol></div>", exp: "1. one\n   - a\n   - b\n2. two\n\n"},
+		"list_references":       {in: "<div class=\"mw-parser-output\"><p>p1</p><ol class=\"references\"><li>ref</li></ol></div>", exp: "p1\n\n"},
+		"definition_list":       {in: "<div class=\"mw-parser-output\"><dl><dt>Term</dt><dd>Definition</dd><dd>Second</dd></dl></div>", exp: "**Term**\n:   Definition\n:   Second\n\n"},
+		"definition_term_only":  {in: "<div class=\"mw-parser-output\"><dl><dt>Pseudo heading</dt></dl><p>p1</p></div>", exp: "**Pseudo heading**\n\np1\n\n"},
+		"definition_multi_line": {in: "<div class=\"mw-parser-output\"><dl><dt>Term</dt><dd><p>first</p><p>second</p></dd></dl></div>", exp: "**Term**\n:   first\n\n    second\n\n"},
+		"blockquote":            {in: "<div class=\"mw-parser-output\"><blockquote><p>quote</p></blockquote></div>", exp: "> quote\n\n"},
+		"blockquote_wrapped":    {in: "<div class=\"mw-parser-output\"><div class=\"Vorlage_Zitat\"><div><blockquote><p>line1</p><p>line2</p></blockquote></div></div></div>", exp: "> line1\n> \n> line2\n\n"},
+		"blockquote_quotebox":   {in: "<div class=\"mw-parser-output\"><div class=\"quotebox\"><blockquote><p>quote</p></blockquote></div></div>", exp: "> quote\n\n"},
+		"blockquote_other_div":  {in: "<div class=\"mw-parser-output\"><div class=\"wrapper\"><p>text</p><blockquote><p>quote</p></blockquote></div></div>", exp: ""},
+
+		"table_simple":          {in: "<div class=\"mw-parser-output\"><table class=\"wikitable\"><tr><th>A</th><th>B</th></tr><tr><td>1</td><td><a href=\"/wiki/Two\">2</a></td></tr></table></div>", exp: "| A | B |\n| --- | --- |\n| 1 | 2 |\n\n"},
+		"table_caption":         {in: "<div class=\"mw-parser-output\"><table class=\"wikitable\"><caption>Cap</caption><tr><th>A</th></tr><tr><td>1</td></tr></table></div>", exp: "Cap\n\n| A |\n| --- |\n| 1 |\n\n"},
+		"table_colspan":         {in: "<div class=\"mw-parser-output\"><table class=\"wikitable\"><tr><th colspan=\"2\">A</th></tr><tr><td>1</td><td>2</td></tr></table></div>", exp: "| A | A |\n| --- | --- |\n| 1 | 2 |\n\n"},
+		"table_rowspan":         {in: "<div class=\"mw-parser-output\"><table class=\"wikitable\"><tr><th>A</th><th>B</th><th>C</th></tr><tr><td rowspan=\"2\">1</td><td>2</td><td>3</td></tr><tr><td>4</td><td>5</td></tr></table></div>", exp: "| A | B | C |\n| --- | --- | --- |\n| 1 | 2 | 3 |\n| 1 | 4 | 5 |\n\n"},
+		"table_rowspan_last":    {in: "<div class=\"mw-parser-output\"><table class=\"wikitable\"><tr><th>A</th><th>B</th><th>C</th></tr><tr><td>1</td><td>2</td><td rowspan=\"3\">3</td></tr><tr><td>4</td></tr><tr><td>5</td><td>6</td></tr></table></div>", exp: "| A | B | C |\n| --- | --- | --- |\n| 1 | 2 | 3 |\n| 4 |  | 3 |\n| 5 | 6 | 3 |\n\n"},
+		"table_ragged_rows":     {in: "<div class=\"mw-parser-output\"><table class=\"wikitable\"><tr><th>A</th><th>B</th></tr><tr><td>1</td></tr></table></div>", exp: "| A | B |\n| --- | --- |\n| 1 |  |\n\n"},
+		"table_cell_escaping":   {in: "<div class=\"mw-parser-output\"><table class=\"wikitable\"><tr><th>A</th></tr><tr><td>1|2<br>3</td></tr></table></div>", exp: "| A |\n| --- |\n| 1\\|2 3 |\n\n"},
+		"table_infobox_ignored": {in: "<div class=\"mw-parser-output\"><table class=\"wikitable infobox\"><tr><th>A</th></tr></table><p>p1</p></div>", exp: "p1\n\n"},
+		"table_plain_ignored":   {in: "<div class=\"mw-parser-output\"><table><tr><td>layout</td></tr></table><p>p1</p></div>", exp: "p1\n\n"},
 	}
 
 	for name, tc := range tests {
@@ -0,0 +1,118 @@
+package wikipedia
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"strconv"
+	"strings"
+)
+
+// WithSections restricts the article to the sections matching any of the selectors. A selector is either the index of
+// a top-level section, starting at 1, or the text of a heading of any level (case-insensitive). A selected section
+// includes all of its subsections. The lead (index 0) is always included unless it is excluded explicitly.
+func WithSections(selectors ...string) Option {
+	return func(p *ArticleParser) {
+		p.sections = append(p.sections, selectors...)
+	}
+}
+
+// WithExcludedSections removes the sections matching any of the selectors, including their subsections. Selectors work
+// the same as in WithSections, use "0" to exclude the lead. Exclusion takes precedence over WithSections.
+func WithExcludedSections(selectors ...string) Option {
+	return func(p *ArticleParser) {
+		p.excludedSections = append(p.excludedSections, selectors...)
+	}
+}
+
+// WithLeadOnly restricts the article to the lead, i.e. the content before the first heading
+func WithLeadOnly() Option {
+	return func(p *ArticleParser) {
+		p.leadOnly = true
+	}
+}
+
+// filterSections removes all nodes of sections which are not selected
+func (p *ArticleParser) filterSections(nodes *goquery.Selection) *goquery.Selection {
+	if len(p.sections) == 0 && len(p.excludedSections) == 0 && !p.leadOnly {
+		return nodes
+	}
+
+	type section struct {
+		level    int
+		included bool
+	}
+
+	leadIncluded := !matchesSection(p.excludedSections, 0, "")
+	// sections which are not matched inherit from their parent, top-level sections from this root
+	rootIncluded := len(p.sections) == 0 && !p.leadOnly
+
+	var path []section
+	var kept []*html.Node
+	topLevelIdx := 0
+
+	for _, n := range nodes.Nodes {
+		level := headingLevel(n)
+		if level == 0 {
+			if len(path) == 0 && leadIncluded || len(path) > 0 && path[len(path)-1].included {
+				kept = append(kept, n)
+			}
+			continue
+		}
+
+		for len(path) > 0 && path[len(path)-1].level >= level {
+			path = path[:len(path)-1]
+		}
+
+		idx := -1 // subsections can only be selected by text
+		if level <= 2 {
+			topLevelIdx++
+			idx = topLevelIdx
+		}
+
+		text := headingText(n)
+		included := rootIncluded
+		if len(path) > 0 {
+			included = path[len(path)-1].included
+		}
+
+		if p.leadOnly || matchesSection(p.excludedSections, idx, text) {
+			included = false
+		} else if matchesSection(p.sections, idx, text) {
+			included = true
+		}
+
+		path = append(path, section{level, included})
+		if included {
+			kept = append(kept, n)
+		}
+	}
+
+	return nodes.FilterNodes(kept...)
+}
+
+// matchesSection returns true if any selector matches the index of a section (0 for the lead) or its heading text
+func matchesSection(selectors []string, idx int, text string) bool {
+	for _, s := range selectors {
+		s = strings.TrimSpace(s)
+		if n, err := strconv.Atoi(s); err == nil {
+			if n == idx {
+				return true
+			}
+			continue
+		}
+
+		if text != "" && strings.EqualFold(s, text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// headingText returns the text of a heading node without edit-links
+func headingText(n *html.Node) string {
+	heading := goquery.NewDocumentFromNode(n).Selection.Clone()
+	heading.Find(".mw-editsection").Remove()
+
+	return strings.Join(strings.Fields(heading.Text()), " ")
+}
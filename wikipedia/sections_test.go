@@ -0,0 +1,45 @@
+package wikipedia
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+const testSections = "<h1 id=\"firstHeading\">Title</h1><div class=\"mw-parser-output\"><p>lead</p>" +
+	"<h2>History<span class=\"mw-editsection\">[edit]</span></h2><p>p1</p><h3>Early</h3><p>p1.1</p><h3>Modern</h3><p>p1.2</p>" +
+	"<div class=\"mw-heading mw-heading2\"><h2>Geography</h2></div><p>p2</p>" +
+	"<h2>Culture</h2><p>p3</p></div>"
+
+func TestSections(t *testing.T) {
+	tests := map[string]struct {
+		opts []Option
+		exp  string
+	}{
+		"all": {opts: nil,
+			exp: "# Title\n\nlead\n\n## History\n\np1\n\n### Early\n\np1.1\n\n### Modern\n\np1.2\n\n## Geography\n\np2\n\n## Culture\n\np3\n\n"},
+		"lead_only": {opts: []Option{WithLeadOnly()}, exp: "# Title\n\nlead\n\n"},
+		"by_index":  {opts: []Option{WithSections("2", "3")}, exp: "# Title\n\nlead\n\n## Geography\n\np2\n\n## Culture\n\np3\n\n"},
+		"by_text":   {opts: []Option{WithSections("history")}, exp: "# Title\n\nlead\n\n## History\n\np1\n\n### Early\n\np1.1\n\n### Modern\n\np1.2\n\n"},
+		"subsection": {opts: []Option{WithSections("Modern", "culture")},
+			exp: "# Title\n\nlead\n\n### Modern\n\np1.2\n\n## Culture\n\np3\n\n"},
+		"exclude": {opts: []Option{WithExcludedSections("Early", "3")},
+			exp: "# Title\n\nlead\n\n## History\n\np1\n\n### Modern\n\np1.2\n\n## Geography\n\np2\n\n"},
+		"exclude_lead": {opts: []Option{WithExcludedSections("0"), WithSections("Geography")},
+			exp: "# Title\n\n## Geography\n\np2\n\n"},
+		"exclude_precedence": {opts: []Option{WithSections("History"), WithExcludedSections("Early")},
+			exp: "# Title\n\nlead\n\n## History\n\np1\n\n### Modern\n\np1.2\n\n"},
+		"no_match": {opts: []Option{WithSections("Missing")}, exp: "# Title\n\nlead\n\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := NewArticleParser(tc.opts...)
+			act, err := p.Parse(io.NopCloser(strings.NewReader(testSections)))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.exp, act)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package wikipedia
+
+import (
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// cellContentAttr is used to hand the converted markdown of a table-cell over to tableConverter. Rules are applied
+// bottom-up, so the cells of a table are always converted before the table itself.
+const cellContentAttr = "data-w2d-cell"
+
+var multipleSpacesRegex = regexp.MustCompile(`\s{2,}`)
+
+var (
+	tableCellConverter = md.Rule{
+		Filter: []string{"th", "td", "caption"},
+		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+			content = strings.ReplaceAll(content, "\n", " ")
+			content = multipleSpacesRegex.ReplaceAllString(content, " ")
+			// pipes may already be escaped by the text rule
+			content = strings.ReplaceAll(strings.ReplaceAll(content, `\|`, "|"), "|", `\|`)
+			selec.SetAttr(cellContentAttr, strings.TrimSpace(content))
+			return md.String("")
+		}}
+
+	tableConverter = md.Rule{
+		Filter: []string{"table"},
+		Replacement: func(content string, selec *goquery.Selection, opt *md.Options) *string {
+			rows := tableRows(selec)
+			if len(rows) == 0 {
+				return md.String("")
+			}
+
+			sb := strings.Builder{}
+			sb.WriteString("\n\n")
+			if caption := selec.ChildrenFiltered("caption").AttrOr(cellContentAttr, ""); caption != "" {
+				sb.WriteString(caption + "\n\n")
+			}
+
+			writeTableRow(&sb, rows[0])
+			sb.WriteString("|" + strings.Repeat(" --- |", len(rows[0])) + "\n")
+			for _, row := range rows[1:] {
+				writeTableRow(&sb, row)
+			}
+			sb.WriteString("\n\n")
+
+			return md.String(sb.String())
+		}}
+)
+
+// tableRows returns the converted cells of table as a grid. Cells spanning multiple rows or columns are duplicated in
+// to every position they cover, so that the result stays a valid pipe-table. All rows are padded to the same width.
+func tableRows(table *goquery.Selection) [][]string {
+	type span struct {
+		content   string
+		remaining int
+	}
+
+	var rows [][]string
+	var spans []span
+	width := 0
+
+	table.Find("tr").Each(func(_ int, tr *goquery.Selection) {
+		if !tr.Closest("table").IsSelection(table) {
+			return // row of a nested table
+		}
+
+		var row []string
+		col := 0
+		// fillSpans copies cells spanning in from the rows above, starting at col
+		fillSpans := func() {
+			for col < len(spans) && spans[col].remaining > 0 {
+				row = append(row, spans[col].content)
+				spans[col].remaining--
+				col++
+			}
+		}
+
+		tr.ChildrenFiltered("th,td").Each(func(_ int, cell *goquery.Selection) {
+			fillSpans()
+			content := cell.AttrOr(cellContentAttr, "")
+			colSpan, rowSpan := spanAttr(cell, "colspan"), spanAttr(cell, "rowspan")
+
+			for i := 0; i < colSpan; i++ {
+				for len(spans) <= col {
+					spans = append(spans, span{})
+				}
+				spans[col] = span{content, rowSpan - 1}
+				row = append(row, content)
+				col++
+			}
+		})
+
+		// cells spanning in to the last columns are kept even if the row ends before them
+		for ; col < len(spans); col++ {
+			if spans[col].remaining == 0 {
+				continue
+			}
+			for len(row) < col {
+				row = append(row, "")
+			}
+			row = append(row, spans[col].content)
+			spans[col].remaining--
+		}
+
+		if len(row) == 0 {
+			return
+		}
+
+		if len(row) > width {
+			width = len(row)
+		}
+		rows = append(rows, row)
+	})
+
+	for i := range rows {
+		for len(rows[i]) < width {
+			rows[i] = append(rows[i], "")
+		}
+	}
+
+	return rows
+}
+
+// spanAttr returns the value of a colspan or rowspan attribute, defaulting to 1 if it is missing or invalid.
+func spanAttr(cell *goquery.Selection, name string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(cell.AttrOr(name, "1")))
+	if err != nil || n < 1 {
+		return 1
+	}
+
+	// guard against absurd values which would blow up the grid
+	if n > 100 {
+		return 100
+	}
+
+	return n
+}
+
+func writeTableRow(sb *strings.Builder, row []string) {
+	sb.WriteString("|")
+	for _, cell := range row {
+		sb.WriteString(" " + cell + " |")
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,120 @@
+package wikipedia
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// headingRegex matches ATX headings, a closing sequence of hashes is only removed if preceded by a space (C#)
+	headingRegex = regexp.MustCompile(`^(#{1,6}) +(.+?)(?: +#+)?\s*$`)
+	fenceRegex   = regexp.MustCompile("^(```|~~~)")
+
+	// inline markdown which is removed from headings, the text of links and images is kept
+	inlineLinkRegex     = regexp.MustCompile(`!?\[((?:[^\]\\]|\\.)*)\](?:\([^)]*\)|\[[^\]]*\])`)
+	footnoteRefRegex    = regexp.MustCompile(`\[\^[^\]]+\]`)
+	emphasisRegex       = regexp.MustCompile("\\*\\*|__|[*`]|\\b_|_\\b")
+	markdownEscapeRegex = regexp.MustCompile(`\\(.)`)
+
+	// linkTextEscaper escapes the characters which would end the text of a link early
+	linkTextEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+)
+
+// InsertTOC inserts a table of contents after the title (the first level-1 heading) of a markdown document. It lists
+// all headings of level 2 to 6 as nested list linking to GitHub-compatible anchors. As it works on markdown it can be
+// applied after translation, to get a table of contents in the target language.
+func InsertTOC(markdown string) string {
+	header, body := SplitFrontMatter(markdown)
+	lines := strings.Split(body, "\n")
+
+	titleIdx := -1
+	slugs := map[string]int{}
+	toc := strings.Builder{}
+	inFence := false
+
+	for i, l := range lines {
+		if fenceRegex.MatchString(l) {
+			inFence = !inFence
+		}
+
+		if inFence {
+			continue
+		}
+
+		m := headingRegex.FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+
+		level := len(m[1])
+		text := plainHeadingText(m[2])
+		slug := uniqueSlug(headingSlug(text), slugs)
+
+		if level == 1 {
+			if titleIdx == -1 {
+				titleIdx = i
+			}
+			continue
+		}
+
+		toc.WriteString(strings.Repeat("  ", level-2) + "- [" + linkTextEscaper.Replace(text) + "](#" + slug + ")\n")
+	}
+
+	if toc.Len() == 0 {
+		return markdown
+	}
+
+	// insert after the title and the blank line following it
+	insertAt := 0
+	if titleIdx != -1 {
+		insertAt = titleIdx + 1
+		if insertAt < len(lines) && strings.TrimSpace(lines[insertAt]) == "" {
+			insertAt++
+		}
+	}
+
+	tocLines := strings.Split(toc.String()+"\n", "\n")
+	tocLines = tocLines[:len(tocLines)-1]
+	lines = append(lines[:insertAt], append(tocLines, lines[insertAt:]...)...)
+
+	return header + strings.Join(lines, "\n")
+}
+
+// plainHeadingText removes inline markdown from the text of a heading
+func plainHeadingText(text string) string {
+	text = footnoteRefRegex.ReplaceAllString(text, "")
+	text = inlineLinkRegex.ReplaceAllString(text, "$1")
+	text = emphasisRegex.ReplaceAllString(text, "")
+	text = markdownEscapeRegex.ReplaceAllString(text, "$1")
+
+	return strings.TrimSpace(text)
+}
+
+// headingSlug returns the anchor GitHub generates for a heading: lower-case, punctuation removed and spaces replaced
+// by hyphens.
+func headingSlug(text string) string {
+	sb := strings.Builder{}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ':
+			sb.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r):
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+// uniqueSlug appends a counter to repeated slugs, as GitHub does
+func uniqueSlug(slug string, seen map[string]int) string {
+	n, ok := seen[slug]
+	seen[slug] = n + 1
+	if !ok {
+		return slug
+	}
+
+	return slug + "-" + strconv.Itoa(n)
+}
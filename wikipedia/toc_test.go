@@ -0,0 +1,45 @@
+package wikipedia
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInsertTOC(t *testing.T) {
+	tests := map[string]struct {
+		in, exp string
+	}{
+		"no_headings": {in: "# Title\n\ntext\n\n", exp: "# Title\n\ntext\n\n"},
+		"nested": {
+			in: "# Title\n\nlead\n\n## History\n\n### Early days\n\ntext\n\n## Geography\n\n",
+			exp: "# Title\n\n- [History](#history)\n  - [Early days](#early-days)\n- [Geography](#geography)\n\n" +
+				"lead\n\n## History\n\n### Early days\n\ntext\n\n## Geography\n\n"},
+		"duplicates_and_unicode": {
+			in: "# Україна\n\n## Історія\n\n## Історія\n\n## Sozial- und Rechtsstaat (1991–2014)\n\n",
+			exp: "# Україна\n\n- [Історія](#історія)\n- [Історія](#історія-1)\n" +
+				"- [Sozial- und Rechtsstaat (1991–2014)](#sozial--und-rechtsstaat-19912014)\n\n" +
+				"## Історія\n\n## Історія\n\n## Sozial- und Rechtsstaat (1991–2014)\n\n"},
+		"inline_markdown": {
+			in: "# Title\n\n## The [_Hearth_](https://example.com) tax[^1]\n\n## 1\\. Rule\n\n",
+			exp: "# Title\n\n- [The Hearth tax](#the-hearth-tax)\n- [1. Rule](#1-rule)\n\n" +
+				"## The [_Hearth_](https://example.com) tax[^1]\n\n## 1\\. Rule\n\n"},
+		"front_matter_and_no_title": {
+			in:  "---\ntitle: \"x\"\n---\n\n## A\n\n",
+			exp: "---\ntitle: \"x\"\n---\n\n- [A](#a)\n\n## A\n\n"},
+		"closing_hashes": {
+			in:  "# T\n\n## C#\n\n## F# ##\n\n## Heat ###\n\n",
+			exp: "# T\n\n- [C#](#c)\n- [F#](#f)\n- [Heat](#heat)\n\n## C#\n\n## F# ##\n\n## Heat ###\n\n"},
+		"brackets": {
+			in:  "# T\n\n## Array\\[0\\] and \\\\ path\n\n",
+			exp: "# T\n\n- [Array\\[0\\] and \\\\ path](#array0-and--path)\n\n## Array\\[0\\] and \\\\ path\n\n"},
+		"code_fence": {
+			in:  "# T\n\n```\n## not a heading\n```\n\n## A\n\n",
+			exp: "# T\n\n- [A](#a)\n\n```\n## not a heading\n```\n\n## A\n\n"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.exp, InsertTOC(tc.in))
+		})
+	}
+}
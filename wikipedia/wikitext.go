@@ -0,0 +1,520 @@
+package wikipedia
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WikitextToHTML converts wikitext in to html resembling the output of the MediaWiki parser, so that it can be read by
+// ArticleParser. Only the basic markup is supported: headings, paragraphs, lists, tables, links, emphasis and
+// references. Templates are dropped, except for citations which are reduced to their title and url. Files, categories
+// and interlanguage links are dropped as well.
+func WikitextToHTML(wikitext string) string {
+	c := &wikitextConverter{refNames: map[string]int{}}
+
+	wikitext = commentRegex.ReplaceAllString(wikitext, "")
+	wikitext = magicWordRegex.ReplaceAllString(wikitext, "")
+	wikitext = replaceEnclosed(wikitext, "{{", "}}", renderTemplate)
+	wikitext = replaceEnclosed(wikitext, "[[", "]]", func(inner string) string {
+		if isDroppedLink(inner) {
+			return ""
+		}
+		return "[[" + inner + "]]"
+	})
+	wikitext = c.extractRefs(wikitext)
+
+	c.sb.WriteString("<div class=\"mw-parser-output\">")
+	for _, line := range strings.Split(wikitext, "\n") {
+		c.line(line)
+	}
+	c.flush()
+	c.writeReferences()
+	c.sb.WriteString("</div>")
+
+	return c.sb.String()
+}
+
+var (
+	commentRegex     = regexp.MustCompile(`(?s)<!--.*?-->`)
+	magicWordRegex   = regexp.MustCompile(`__[A-Z]+__`)
+	refRegex         = regexp.MustCompile(`(?s)<ref(\s[^>]*?)?(?:/>|>(.*?)</ref>)`)
+	refNameRegex     = regexp.MustCompile(`name\s*=\s*"?([^">/]+?)"?\s*$`)
+	referencesTag    = regexp.MustCompile(`<references\s*/>|<references>.*?</references>`)
+	wikiHeadingRegex = regexp.MustCompile(`^(={2,6})\s*(.+?)\s*(={2,6})\s*$`)
+	wikiListRegex    = regexp.MustCompile(`^([*#:;]+)\s*(.*)$`)
+	wikiLinkRegex    = regexp.MustCompile(`\[\[([^\[\]|]+)(?:\|([^\[\]]*))?\]\](\p{Ll}*)`)
+	wikiExtLinkRegex = regexp.MustCompile(`\[((?:https?:)?//[^\s\]]+)(?:\s+([^\]]*))?\]`)
+)
+
+// droppedNamespaces are the (lower case) prefixes of links which are not rendered as links: files and categories
+var droppedNamespaces = map[string]bool{
+	"file": true, "image": true, "media": true, "category": true,
+	"datei": true, "bild": true, "kategorie": true,
+	"fichier": true, "catégorie": true, "archivo": true, "categoría": true, "файл": true, "категория": true,
+}
+
+// isDroppedLink returns true for links to files, categories and interlanguage links ([[de:Herd]])
+func isDroppedLink(inner string) bool {
+	i := strings.Index(inner, ":")
+	if i <= 0 {
+		return false
+	}
+
+	prefix := strings.ToLower(strings.TrimSpace(inner[:i]))
+	_, _, interwiki := splitLangPrefix(inner)
+	return droppedNamespaces[prefix] || interwiki
+}
+
+// replaceEnclosed replaces every (possibly nested) occurrence of open ... close in s by the result of fn. Nested
+// occurrences are replaced first and fn receives the content between the delimiters.
+func replaceEnclosed(s, open, close string, fn func(inner string) string) string {
+	start := strings.Index(s, open)
+	if start == -1 {
+		return s
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(s[:start])
+	rest := s[start+len(open):]
+	depth := 1
+	pos := 0
+	for depth > 0 {
+		o := strings.Index(rest[pos:], open)
+		c := strings.Index(rest[pos:], close)
+		switch {
+		case c == -1:
+			// unbalanced, keep the remaining text
+			sb.WriteString(open + replaceEnclosed(rest, open, close, fn))
+			return sb.String()
+		case o != -1 && o < c:
+			depth++
+			pos += o + len(open)
+		default:
+			depth--
+			pos += c + len(close)
+		}
+	}
+
+	sb.WriteString(fn(replaceEnclosed(rest[:pos-len(close)], open, close, fn)))
+	sb.WriteString(replaceEnclosed(rest[pos:], open, close, fn))
+
+	return sb.String()
+}
+
+// renderTemplate drops templates, only citations are kept as (external) link with the cited title
+func renderTemplate(inner string) string {
+	params := splitParams(inner)
+	name := strings.ToLower(strings.TrimSpace(params[0]))
+	if !strings.HasPrefix(name, "cite") && name != "literatur" && name != "internetquelle" {
+		return ""
+	}
+
+	var title, link string
+	for _, p := range params[1:] {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimSpace(kv[0])) {
+		case "title", "titel":
+			title = linkText(strings.TrimSpace(kv[1]))
+		case "url":
+			link = strings.TrimSpace(kv[1])
+		}
+	}
+
+	if link != "" && title != "" {
+		return "[" + link + " " + title + "]"
+	}
+
+	return title + link
+}
+
+// linkText replaces all links in s by their text
+func linkText(s string) string {
+	return wikiLinkRegex.ReplaceAllStringFunc(s, func(link string) string {
+		m := wikiLinkRegex.FindStringSubmatch(link)
+		if m[2] != "" {
+			return m[2] + m[3]
+		}
+		return strings.TrimPrefix(m[1], ":") + m[3]
+	})
+}
+
+// splitParams splits the content of a template or table cell at every | which is not part of a link
+func splitParams(s string) []string {
+	var params []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "[["):
+			depth++
+			i++
+		case strings.HasPrefix(s[i:], "]]") && depth > 0:
+			depth--
+			i++
+		case s[i] == '|' && depth == 0:
+			params = append(params, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(params, s[start:])
+}
+
+type wikitextConverter struct {
+	sb strings.Builder
+	// refs holds the wikitext of the references, the reference with number n at index n-1
+	refs     []string
+	refNames map[string]int
+	// listItems holds the markup characters (*#:;) of the currently open list items
+	listItems string
+	paragraph []string
+	table     *wikitextTable
+}
+
+// extractRefs replaces all references with citation markers and collects their content
+func (c *wikitextConverter) extractRefs(s string) string {
+	s = referencesTag.ReplaceAllString(s, "")
+	return refRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		m := refRegex.FindStringSubmatch(ref)
+		name := ""
+		if nm := refNameRegex.FindStringSubmatch(strings.TrimSpace(m[1])); nm != nil {
+			name = nm[1]
+		}
+
+		n, ok := c.refNames[name]
+		if !ok || name == "" {
+			c.refs = append(c.refs, strings.TrimSpace(m[2]))
+			n = len(c.refs)
+			if name != "" {
+				c.refNames[name] = n
+			}
+		} else if c.refs[n-1] == "" {
+			c.refs[n-1] = strings.TrimSpace(m[2])
+		}
+
+		id := strconv.Itoa(n)
+		return "<sup class=\"reference\"><a href=\"#cite_note-" + id + "\">[" + id + "]</a></sup>"
+	})
+}
+
+func (c *wikitextConverter) line(line string) {
+	trimmed := strings.TrimSpace(line)
+
+	if c.table != nil || strings.HasPrefix(trimmed, "{|") {
+		c.flush()
+		c.tableLine(trimmed)
+		return
+	}
+
+	if m := wikiHeadingRegex.FindStringSubmatch(trimmed); m != nil && len(m[1]) == len(m[3]) {
+		c.flush()
+		tag := "h" + strconv.Itoa(len(m[1]))
+		c.sb.WriteString("<" + tag + ">" + inlineWikitext(m[2]) + "</" + tag + ">")
+		return
+	}
+
+	if m := wikiListRegex.FindStringSubmatch(line); m != nil {
+		c.flushParagraph()
+		items, content := m[1], m[2]
+		if items[len(items)-1] == ';' {
+			if i := definitionSeparator(content); i != -1 {
+				c.listItem(items, content[:i])
+				c.listItem(items[:len(items)-1]+":", content[i+1:])
+				return
+			}
+		}
+		c.listItem(items, content)
+		return
+	}
+
+	if trimmed == "" || strings.HasPrefix(trimmed, "----") {
+		c.flush()
+		return
+	}
+
+	c.closeLists("")
+	c.paragraph = append(c.paragraph, trimmed)
+}
+
+// definitionSeparator returns the index of the colon separating term and definition in ";term : definition"
+func definitionSeparator(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "[["):
+			depth++
+		case strings.HasPrefix(s[i:], "]]") && depth > 0:
+			depth--
+		case s[i] == ':' && depth == 0 && !strings.HasPrefix(s[i:], "://"):
+			return i
+		}
+	}
+
+	return -1
+}
+
+// flush closes all open paragraphs and lists
+func (c *wikitextConverter) flush() {
+	c.flushParagraph()
+	c.closeLists("")
+}
+
+func (c *wikitextConverter) flushParagraph() {
+	if len(c.paragraph) == 0 {
+		return
+	}
+
+	c.sb.WriteString("<p>" + inlineWikitext(strings.Join(c.paragraph, " ")) + "</p>")
+	c.paragraph = nil
+}
+
+// listItem opens the list item described by the markup characters items, closing and opening lists as necessary
+func (c *wikitextConverter) listItem(items, content string) {
+	common := 0
+	for common < len(c.listItems) && common < len(items) && listTag(c.listItems[common]) == listTag(items[common]) {
+		common++
+	}
+
+	c.closeLists(items[:common])
+	if common == len(items) {
+		// next item of the innermost list
+		last := len(items) - 1
+		c.sb.WriteString("</" + itemTag(c.listItems[last]) + "><" + itemTag(items[last]) + ">")
+	}
+
+	for i := common; i < len(items); i++ {
+		c.sb.WriteString("<" + listTag(items[i]) + "><" + itemTag(items[i]) + ">")
+	}
+
+	c.sb.WriteString(inlineWikitext(content))
+	c.listItems = items
+}
+
+// closeLists closes all open lists which are nested deeper than keep
+func (c *wikitextConverter) closeLists(keep string) {
+	for i := len(c.listItems) - 1; i >= len(keep); i-- {
+		c.sb.WriteString("</" + itemTag(c.listItems[i]) + "></" + listTag(c.listItems[i]) + ">")
+	}
+	c.listItems = c.listItems[:len(keep)]
+}
+
+func listTag(item byte) string {
+	switch item {
+	case '*':
+		return "ul"
+	case '#':
+		return "ol"
+	}
+	return "dl"
+}
+
+func itemTag(item byte) string {
+	switch item {
+	case ';':
+		return "dt"
+	case ':':
+		return "dd"
+	}
+	return "li"
+}
+
+type wikitextCell struct {
+	tag, attrs, content string
+}
+
+// wikitextTable collects the rows of a table. Nested tables are skipped.
+type wikitextTable struct {
+	attrs   string
+	caption string
+	rows    [][]wikitextCell
+	depth   int
+}
+
+func (c *wikitextConverter) tableLine(line string) {
+	t := c.table
+	switch {
+	case strings.HasPrefix(line, "{|"):
+		if t != nil {
+			t.depth++
+			return
+		}
+		c.table = &wikitextTable{attrs: strings.TrimSpace(line[2:])}
+	case t.depth > 0:
+		if strings.HasPrefix(line, "|}") {
+			t.depth--
+		}
+	case strings.HasPrefix(line, "|}"):
+		c.sb.WriteString(t.html())
+		c.table = nil
+	case strings.HasPrefix(line, "|+"):
+		t.caption = inlineWikitext(strings.TrimSpace(line[2:]))
+	case strings.HasPrefix(line, "|-"):
+		t.rows = append(t.rows, nil)
+	case strings.HasPrefix(line, "!"):
+		t.addCells("th", line[1:], "!!")
+	case strings.HasPrefix(line, "|"):
+		t.addCells("td", line[1:], "||")
+	default:
+		// continuation of the last cell
+		if len(t.rows) > 0 && len(t.rows[len(t.rows)-1]) > 0 {
+			row := t.rows[len(t.rows)-1]
+			row[len(row)-1].content += " " + line
+		}
+	}
+}
+
+func (t *wikitextTable) addCells(tag, line, sep string) {
+	if len(t.rows) == 0 {
+		t.rows = append(t.rows, nil)
+	}
+
+	for _, cell := range strings.Split(line, sep) {
+		attrs, content := "", cell
+		// attributes are separated from the content by a single pipe: | colspan="2" | content
+		if parts := splitParams(cell); len(parts) > 1 && strings.Contains(parts[0], "=") {
+			attrs, content = strings.TrimSpace(parts[0]), strings.Join(parts[1:], "|")
+		}
+		t.rows[len(t.rows)-1] = append(t.rows[len(t.rows)-1], wikitextCell{tag, attrs, strings.TrimSpace(content)})
+	}
+}
+
+func (t *wikitextTable) html() string {
+	sb := strings.Builder{}
+	sb.WriteString("<table" + withSpace(t.attrs) + ">")
+	if t.caption != "" {
+		sb.WriteString("<caption>" + t.caption + "</caption>")
+	}
+
+	for _, row := range t.rows {
+		if len(row) == 0 {
+			continue
+		}
+
+		sb.WriteString("<tr>")
+		for _, cell := range row {
+			sb.WriteString("<" + cell.tag + withSpace(cell.attrs) + ">" + inlineWikitext(cell.content) + "</" + cell.tag + ">")
+		}
+		sb.WriteString("</tr>")
+	}
+	sb.WriteString("</table>")
+
+	return sb.String()
+}
+
+// withSpace returns the attributes with a leading space, if there are any
+func withSpace(attrs string) string {
+	if attrs == "" {
+		return ""
+	}
+	return " " + attrs
+}
+
+// writeReferences writes the list of references in the form used by the MediaWiki parser
+func (c *wikitextConverter) writeReferences() {
+	if len(c.refs) == 0 {
+		return
+	}
+
+	c.sb.WriteString("<ol class=\"references\">")
+	for i, ref := range c.refs {
+		c.sb.WriteString("<li id=\"cite_note-" + strconv.Itoa(i+1) + "\"><span class=\"reference-text\">" +
+			inlineWikitext(ref) + "</span></li>")
+	}
+	c.sb.WriteString("</ol>")
+}
+
+// inlineWikitext converts links and emphasis in s
+func inlineWikitext(s string) string {
+	s = wikiLinkRegex.ReplaceAllStringFunc(s, func(link string) string {
+		m := wikiLinkRegex.FindStringSubmatch(link)
+		target, text := strings.TrimSpace(m[1]), m[2]
+		if text == "" {
+			text = strings.TrimPrefix(target, ":")
+		}
+		text += m[3]
+
+		if strings.HasPrefix(target, "#") {
+			return text
+		}
+
+		fragment := ""
+		if i := strings.Index(target, "#"); i != -1 {
+			target, fragment = target[:i], "#"+strings.ReplaceAll(strings.TrimSpace(target[i+1:]), " ", "_")
+		}
+
+		return "<a href=\"/wiki/" + titlePath(upperFirst(strings.TrimPrefix(target, ":"))) + fragment + "\">" + text + "</a>"
+	})
+
+	s = wikiExtLinkRegex.ReplaceAllStringFunc(s, func(link string) string {
+		m := wikiExtLinkRegex.FindStringSubmatch(link)
+		text := m[2]
+		if text == "" {
+			text = m[1]
+		}
+
+		return "<a class=\"external\" href=\"" + m[1] + "\">" + text + "</a>"
+	})
+
+	return emphasis(s)
+}
+
+// emphasis converts ”italic”, ”'bold”' and ””'both””' in to html. Unclosed tags are closed at the end of s.
+func emphasis(s string) string {
+	sb := strings.Builder{}
+	var bold, italic bool
+	toggle := func(open *bool, tag string) {
+		if *open {
+			sb.WriteString("</" + tag + ">")
+		} else {
+			sb.WriteString("<" + tag + ">")
+		}
+		*open = !*open
+	}
+
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "'''''") && bold && italic:
+			toggle(&italic, "i")
+			toggle(&bold, "b")
+			i += 5
+		case strings.HasPrefix(s[i:], "'''''"):
+			toggle(&bold, "b")
+			toggle(&italic, "i")
+			i += 5
+		case strings.HasPrefix(s[i:], "'''"):
+			toggle(&bold, "b")
+			i += 3
+		case strings.HasPrefix(s[i:], "''"):
+			toggle(&italic, "i")
+			i += 2
+		default:
+			sb.WriteByte(s[i])
+			i++
+		}
+	}
+
+	if italic {
+		sb.WriteString("</i>")
+	}
+	if bold {
+		sb.WriteString("</b>")
+	}
+
+	return sb.String()
+}
+
+// upperFirst returns s with its first letter in upper case, as MediaWiki does with titles
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+
+	return string(unicode.ToUpper(r)) + s[size:]
+}
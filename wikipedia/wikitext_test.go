@@ -0,0 +1,40 @@
+package wikipedia
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWikitextToHTML(t *testing.T) {
+	tests := map[string]struct {
+		in, exp string
+	}{
+		"paragraphs": {in: "First\nline\n\nSecond",
+			exp: "<p>First line</p><p>Second</p>"},
+		"headings": {in: "== History ==\n=== Early ===\nText",
+			exp: "<h2>History</h2><h3>Early</h3><p>Text</p>"},
+		"emphasis": {in: "'''bold''' ''italic'' '''''both''''' ''unclosed",
+			exp: "<p><b>bold</b> <i>italic</i> <b><i>both</i></b> <i>unclosed</i></p>"},
+		"links": {in: "[[fire]]s, [[Smoke (thing)|smoke]], [[Fire#History|history]], [[#Uses|uses]], [https://example.com ext]",
+			exp: "<p><a href=\"/wiki/Fire\">fires</a>, <a href=\"/wiki/Smoke_%28thing%29\">smoke</a>, " +
+				"<a href=\"/wiki/Fire#History\">history</a>, uses, <a class=\"external\" href=\"https://example.com\">ext</a></p>"},
+		"dropped": {in: "A{{Infobox|a={{b}}}} [[File:X.png|thumb|a [[b]]]]B<!-- c -->__NOTOC__ [[Category:Fire]][[de:Feuer]]",
+			exp: "<p>A B</p>"},
+		"lists": {in: "* one\n** two\n* three\n# four\n;term:def",
+			exp: "<ul><li>one<ul><li>two</li></ul></li><li>three</li></ul><ol><li>four</li></ol><dl><dt>term</dt><dd>def</dd></dl>"},
+		"table": {in: "{| class=\"wikitable\"\n|+ Caption\n! A !! B\n|-\n| 1 || colspan=\"2\" | [[c|d]]\n|}",
+			exp: "<table class=\"wikitable\"><caption>Caption</caption><tr><th>A</th><th>B</th></tr>" +
+				"<tr><td>1</td><td colspan=\"2\"><a href=\"/wiki/C\">d</a></td></tr></table>"},
+		"references": {in: "A<ref name=\"a\">{{cite web|url=https://example.com|title=X [[y]]}}</ref> B<ref>Plain</ref> C<ref name=\"a\" />\n<references />",
+			exp: "<p>A<sup class=\"reference\"><a href=\"#cite_note-1\">[1]</a></sup> B<sup class=\"reference\"><a href=\"#cite_note-2\">[2]</a></sup> " +
+				"C<sup class=\"reference\"><a href=\"#cite_note-1\">[1]</a></sup></p><ol class=\"references\">" +
+				"<li id=\"cite_note-1\"><span class=\"reference-text\"><a class=\"external\" href=\"https://example.com\">X y</a></span></li>" +
+				"<li id=\"cite_note-2\"><span class=\"reference-text\">Plain</span></li></ol>"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, "<div class=\"mw-parser-output\">"+tc.exp+"</div>", WikitextToHTML(tc.in))
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package wikipedia
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// zimMagic is the magic number every ZIM file starts with
+const zimMagic = 0x044D495A
+
+// maxZIMRedirects is the number of redirects followed by ZIM.Fetch before giving up
+const maxZIMRedirects = 5
+
+// mime type indices with special meaning in directory entries
+const (
+	zimRedirect   = 0xffff
+	zimLinkTarget = 0xfffe
+	zimDeleted    = 0xfffd
+)
+
+// compression types of clusters
+const (
+	zimUncompressed  = 1
+	zimXZ            = 4
+	zimZstd          = 5
+	zimExtendedBlobs = 0x10
+)
+
+// zimHeader is the header at the start of a ZIM file, see https://wiki.openzim.org/wiki/ZIM_file_format
+type zimHeader struct {
+	Magic         uint32
+	MajorVersion  uint16
+	MinorVersion  uint16
+	UUID          [16]byte
+	EntryCount    uint32
+	ClusterCount  uint32
+	URLPtrPos     uint64
+	TitlePtrPos   uint64
+	ClusterPtrPos uint64
+	MimeListPos   uint64
+	MainPage      uint32
+	LayoutPage    uint32
+	ChecksumPos   uint64
+}
+
+// zimEntry is a directory entry of a ZIM file
+type zimEntry struct {
+	mimeType  uint16
+	namespace byte
+	// cluster and blob locate the content of the entry, redirect is the index of the target entry for redirects
+	cluster, blob, redirect uint32
+	url, title              string
+}
+
+func (e zimEntry) isRedirect() bool {
+	return e.mimeType == zimRedirect
+}
+
+// displayTitle returns the title of the entry, which defaults to its url
+func (e zimEntry) displayTitle() string {
+	if e.title == "" {
+		return e.url
+	}
+	return e.title
+}
+
+// ZIM reads articles from a ZIM archive as used by Kiwix (e.g. wikipedia_en_all_maxi.zim). The archives contain the
+// rendered html of the articles.
+type ZIM struct {
+	f         *os.File
+	header    zimHeader
+	mimeTypes []string
+	// namespace is the namespace of the articles, C in newer archives and A in older ones
+	namespace byte
+}
+
+// OpenZIM opens the ZIM archive at path, it has to be closed after use
+func OpenZIM(path string) (*ZIM, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	z := &ZIM{f: f, namespace: 'A'}
+	if err := z.readHeader(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read %s: %s", path, err)
+	}
+
+	return z, nil
+}
+
+func (z *ZIM) Close() error {
+	return z.f.Close()
+}
+
+func (z *ZIM) readHeader() error {
+	err := binary.Read(io.NewSectionReader(z.f, 0, 80), binary.LittleEndian, &z.header)
+	if err != nil {
+		return err
+	}
+
+	if z.header.Magic != zimMagic {
+		return errors.New("not a ZIM file")
+	}
+
+	if z.header.MajorVersion > 6 || z.header.MajorVersion == 6 && z.header.MinorVersion >= 1 {
+		z.namespace = 'C'
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(z.f, int64(z.header.MimeListPos), 1<<20))
+	for {
+		mimeType, err := r.ReadString(0)
+		if err != nil {
+			return fmt.Errorf("invalid mime type list: %s", err)
+		}
+		if mimeType == "\x00" {
+			return nil
+		}
+		z.mimeTypes = append(z.mimeTypes, strings.TrimSuffix(mimeType, "\x00"))
+	}
+}
+
+// Fetch returns the article with the given title, optionally prefixed with the language of the archive (en:Hearth).
+// Redirects are followed. The url of the article is derived from the Source metadata of the archive, if present.
+func (z *ZIM) Fetch(title string) (*Page, error) {
+	lang, t, _ := splitLangPrefix(title)
+	normalized := upperFirst(NormalizeTitle(t))
+	if normalized == "" {
+		return nil, fmt.Errorf("invalid article %q", title)
+	}
+
+	host := z.sourceHost()
+	if archiveLang := (wiki{"https", host}).language(); lang != "" && archiveLang != "" && lang != archiveLang {
+		return nil, fmt.Errorf("the archive %s is of the %s wikipedia, not %s", z.f.Name(), archiveLang, lang)
+	}
+
+	i, err := z.find(normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := z.entry(i)
+	for n := 0; err == nil && e.isRedirect(); n++ {
+		if n == maxZIMRedirects {
+			return nil, fmt.Errorf("too many redirects for %q", title)
+		}
+		e, err = z.entry(e.redirect)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if e.mimeType >= uint16(len(z.mimeTypes)) || !strings.HasPrefix(z.mimeTypes[e.mimeType], "text/html") {
+		return nil, fmt.Errorf("%q is not an article", title)
+	}
+
+	content, err := z.blob(e.cluster, e.blob)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Page{Title: strings.ReplaceAll(e.displayTitle(), "_", " "), HTML: string(content)}
+	if host != "" {
+		p.URL = wiki{"https", host}.pageURL(p.Title)
+		p.Language = wiki{"https", host}.language()
+	}
+
+	return p, nil
+}
+
+// find returns the index of the article with the given title. Articles are looked up by their url (the title with
+// underscores) first and then by their title.
+func (z *ZIM) find(title string) (uint32, error) {
+	if i, ok, err := z.search(z.namespace, strings.ReplaceAll(title, " ", "_"), z.urlEntry, zimEntry.urlKey); ok || err != nil {
+		return i, err
+	}
+
+	if i, ok, err := z.search(z.namespace, title, z.titleEntry, zimEntry.titleKey); ok || err != nil {
+		return i, err
+	}
+
+	return 0, fmt.Errorf("%q not found in %s", title, z.f.Name())
+}
+
+func (e zimEntry) urlKey() string {
+	return e.url
+}
+
+func (e zimEntry) titleKey() string {
+	return e.displayTitle()
+}
+
+// search does a binary search for the entry with namespace and key over one of the sorted pointer lists. lookup returns
+// the entry and its index in the url pointer list for a position in the list.
+func (z *ZIM) search(namespace byte, key string, lookup func(pos uint32) (uint32, zimEntry, error), entryKey func(zimEntry) string) (uint32, bool, error) {
+	var err error
+	target := string(namespace) + key
+	pos := uint32(sort.Search(int(z.header.EntryCount), func(pos int) bool {
+		if err != nil {
+			return true
+		}
+
+		var e zimEntry
+		_, e, err = lookup(uint32(pos))
+		return string(e.namespace)+entryKey(e) >= target
+	}))
+	if err != nil || pos >= z.header.EntryCount {
+		return 0, false, err
+	}
+
+	i, e, err := lookup(pos)
+	if err != nil || string(e.namespace)+entryKey(e) != target {
+		return 0, false, err
+	}
+
+	return i, true, nil
+}
+
+// urlEntry returns the entry at pos of the url pointer list
+func (z *ZIM) urlEntry(pos uint32) (uint32, zimEntry, error) {
+	e, err := z.entry(pos)
+	return pos, e, err
+}
+
+// titleEntry returns the entry at pos of the title pointer list
+func (z *ZIM) titleEntry(pos uint32) (uint32, zimEntry, error) {
+	var i uint32
+	if err := z.readAt(int64(z.header.TitlePtrPos)+4*int64(pos), &i); err != nil {
+		return 0, zimEntry{}, err
+	}
+
+	e, err := z.entry(i)
+	return i, e, err
+}
+
+// entry reads the directory entry with index i of the url pointer list
+func (z *ZIM) entry(i uint32) (zimEntry, error) {
+	if i >= z.header.EntryCount {
+		return zimEntry{}, fmt.Errorf("invalid entry %d", i)
+	}
+
+	var pos uint64
+	if err := z.readAt(int64(z.header.URLPtrPos)+8*int64(i), &pos); err != nil {
+		return zimEntry{}, err
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(z.f, int64(pos), 1<<16))
+	var head struct {
+		MimeType  uint16
+		ParamLen  uint8
+		Namespace byte
+		Revision  uint32
+		Target    uint32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &head); err != nil {
+		return zimEntry{}, err
+	}
+
+	e := zimEntry{mimeType: head.MimeType, namespace: head.Namespace}
+	switch head.MimeType {
+	case zimRedirect:
+		e.redirect = head.Target
+	case zimLinkTarget, zimDeleted:
+	default:
+		e.cluster = head.Target
+		if err := binary.Read(r, binary.LittleEndian, &e.blob); err != nil {
+			return zimEntry{}, err
+		}
+	}
+
+	var err error
+	if e.url, err = r.ReadString(0); err != nil {
+		return zimEntry{}, err
+	}
+	if e.title, err = r.ReadString(0); err != nil {
+		return zimEntry{}, err
+	}
+	e.url, e.title = strings.TrimSuffix(e.url, "\x00"), strings.TrimSuffix(e.title, "\x00")
+
+	return e, nil
+}
+
+// blob returns the (decompressed) content of a blob in a cluster
+func (z *ZIM) blob(cluster, blob uint32) ([]byte, error) {
+	if cluster >= z.header.ClusterCount {
+		return nil, fmt.Errorf("invalid cluster %d", cluster)
+	}
+
+	var start, end uint64
+	if err := z.readAt(int64(z.header.ClusterPtrPos)+8*int64(cluster), &start); err != nil {
+		return nil, err
+	}
+	end = z.header.ChecksumPos
+	if cluster+1 < z.header.ClusterCount {
+		if err := z.readAt(int64(z.header.ClusterPtrPos)+8*int64(cluster+1), &end); err != nil {
+			return nil, err
+		}
+	}
+	if end <= start {
+		return nil, fmt.Errorf("invalid cluster %d", cluster)
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(z.f, int64(start), int64(end-start)))
+	info, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	var data io.Reader
+	switch info & 0x0f {
+	case 0, zimUncompressed:
+		data = r
+	case zimXZ:
+		if data, err = xz.NewReader(r); err != nil {
+			return nil, err
+		}
+	case zimZstd:
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer dec.Close()
+		data = dec
+	default:
+		return nil, fmt.Errorf("unsupported compression %d of cluster %d", info&0x0f, cluster)
+	}
+
+	content, err := io.ReadAll(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress cluster %d: %s", cluster, err)
+	}
+
+	return clusterBlob(content, blob, info&zimExtendedBlobs != 0)
+}
+
+// clusterBlob returns a blob of the decompressed data of a cluster, which starts with the offsets of the blobs
+func clusterBlob(data []byte, blob uint32, extended bool) ([]byte, error) {
+	size := 4
+	if extended {
+		size = 8
+	}
+
+	offset := func(i int) uint64 {
+		if i*size+size > len(data) {
+			return 0
+		}
+		if extended {
+			return binary.LittleEndian.Uint64(data[i*size:])
+		}
+		return uint64(binary.LittleEndian.Uint32(data[i*size:]))
+	}
+
+	count := int(offset(0))/size - 1
+	if int(blob) >= count {
+		return nil, fmt.Errorf("invalid blob %d", blob)
+	}
+
+	start, end := offset(int(blob)), offset(int(blob)+1)
+	if start > end || end > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid blob %d", blob)
+	}
+
+	return data[start:end], nil
+}
+
+// sourceHost returns the host of the wiki the archive was created from, taken from its Source metadata
+func (z *ZIM) sourceHost() string {
+	i, ok, err := z.search('M', "Source", z.urlEntry, zimEntry.urlKey)
+	if err != nil || !ok {
+		return ""
+	}
+
+	e, err := z.entry(i)
+	if err != nil || e.isRedirect() {
+		return ""
+	}
+
+	source, err := z.blob(e.cluster, e.blob)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.TrimSpace(string(source))
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	if i := strings.Index(host, "/"); i != -1 {
+		host = host[:i]
+	}
+
+	return host
+}
+
+func (z *ZIM) readAt(pos int64, v interface{}) error {
+	return binary.Read(io.NewSectionReader(z.f, pos, int64(binary.Size(v))), binary.LittleEndian, v)
+}
@@ -0,0 +1,188 @@
+package wikipedia
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/ulikunitz/xz"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testZIMEntry struct {
+	namespace  byte
+	url, title string
+	// mimeType is an index in to testZIMMimeTypes, content is ignored for redirects
+	mimeType uint16
+	redirect uint32
+	content  string
+}
+
+var testZIMMimeTypes = []string{"text/html", "text/plain", "image/png"}
+
+// writeTestZIM writes a ZIM archive with the given entries, which have to be sorted by namespace and url as well as by
+// namespace and title. The content of all entries is stored in a single cluster.
+func writeTestZIM(t *testing.T, major, minor uint16, compression byte, entries []testZIMEntry) string {
+	var mimeList bytes.Buffer
+	for _, m := range testZIMMimeTypes {
+		mimeList.WriteString(m + "\x00")
+	}
+	mimeList.WriteByte(0)
+
+	// blob offsets followed by the blobs
+	var blobs [][]byte
+	var dirents [][]byte
+	for _, e := range entries {
+		var d bytes.Buffer
+		for _, v := range []interface{}{e.mimeType, uint8(0), e.namespace, uint32(0)} {
+			_ = binary.Write(&d, binary.LittleEndian, v)
+		}
+		if e.mimeType == zimRedirect {
+			_ = binary.Write(&d, binary.LittleEndian, e.redirect)
+		} else {
+			_ = binary.Write(&d, binary.LittleEndian, [2]uint32{0, uint32(len(blobs))})
+			blobs = append(blobs, []byte(e.content))
+		}
+		d.WriteString(e.url + "\x00" + e.title + "\x00")
+		dirents = append(dirents, d.Bytes())
+	}
+
+	var data bytes.Buffer
+	offset := uint32(4 * (len(blobs) + 1))
+	for _, b := range blobs {
+		_ = binary.Write(&data, binary.LittleEndian, offset)
+		offset += uint32(len(b))
+	}
+	_ = binary.Write(&data, binary.LittleEndian, offset)
+	for _, b := range blobs {
+		data.Write(b)
+	}
+
+	cluster := bytes.NewBuffer([]byte{compression})
+	switch compression {
+	case zimXZ:
+		w, err := xz.NewWriter(cluster)
+		assert.NoError(t, err)
+		_, _ = w.Write(data.Bytes())
+		assert.NoError(t, w.Close())
+	case zimZstd:
+		w, err := zstd.NewWriter(cluster)
+		assert.NoError(t, err)
+		_, _ = w.Write(data.Bytes())
+		assert.NoError(t, w.Close())
+	default:
+		cluster.Write(data.Bytes())
+	}
+
+	h := zimHeader{Magic: zimMagic, MajorVersion: major, MinorVersion: minor, EntryCount: uint32(len(entries)), ClusterCount: 1}
+	h.MimeListPos = 80
+	h.URLPtrPos = h.MimeListPos + uint64(mimeList.Len())
+	h.TitlePtrPos = h.URLPtrPos + 8*uint64(len(entries))
+	direntPos := h.TitlePtrPos + 4*uint64(len(entries))
+
+	var urlPtrs, titlePtrs, direntData bytes.Buffer
+	for i, d := range dirents {
+		_ = binary.Write(&urlPtrs, binary.LittleEndian, direntPos+uint64(direntData.Len()))
+		_ = binary.Write(&titlePtrs, binary.LittleEndian, uint32(i))
+		direntData.Write(d)
+	}
+	h.ClusterPtrPos = direntPos + uint64(direntData.Len())
+	clusterPos := h.ClusterPtrPos + 8
+	h.ChecksumPos = clusterPos + uint64(cluster.Len())
+
+	var f bytes.Buffer
+	_ = binary.Write(&f, binary.LittleEndian, h)
+	f.Write(mimeList.Bytes())
+	f.Write(urlPtrs.Bytes())
+	f.Write(titlePtrs.Bytes())
+	f.Write(direntData.Bytes())
+	_ = binary.Write(&f, binary.LittleEndian, clusterPos)
+	f.Write(cluster.Bytes())
+	f.Write(make([]byte, 16))
+
+	path := filepath.Join(t.TempDir(), "test.zim")
+	assert.NoError(t, os.WriteFile(path, f.Bytes(), 0644))
+
+	return path
+}
+
+func TestZIMFetch(t *testing.T) {
+	const html = "<html lang=\"en\"><body><div class=\"mw-parser-output\"><p><a href=\"./Hearth\">Hearth</a> fire</p></div></body></html>"
+	entries := func(ns byte) []testZIMEntry {
+		return []testZIMEntry{
+			{namespace: ns, url: "Fire", title: "Fire", content: html},
+			{namespace: ns, url: "Fires", title: "Fires", mimeType: zimRedirect, redirect: 0},
+			{namespace: ns, url: "Logo.png", mimeType: 2, content: "png"},
+			{namespace: ns, url: "Loop", mimeType: zimRedirect, redirect: 3},
+			{namespace: ns, url: "Smoke_page", title: "Smoke", content: html},
+			{namespace: 'M', url: "Source", mimeType: 1, content: "https://en.wikipedia.org/"},
+		}
+	}
+
+	archives := map[string]string{
+		"uncompressed": writeTestZIM(t, 6, 1, zimUncompressed, entries('C')),
+		"xz":           writeTestZIM(t, 6, 1, zimXZ, entries('C')),
+		"zstd":         writeTestZIM(t, 6, 1, zimZstd, entries('C')),
+		"old":          writeTestZIM(t, 5, 0, zimZstd, entries('A')),
+	}
+
+	tests := map[string]struct {
+		title, exp string
+		err        string
+	}{
+		"url":          {title: "Fire", exp: "Fire"},
+		"lower_case":   {title: "fire", exp: "Fire"},
+		"redirect":     {title: "Fires", exp: "Fire"},
+		"title":        {title: "Smoke", exp: "Smoke"},
+		"missing":      {title: "Hearth", err: "\"Hearth\" not found"},
+		"loop":         {title: "Loop", err: "too many redirects"},
+		"no_article":   {title: "Logo.png", err: "not an article"},
+		"empty":        {title: " ", err: "invalid article"},
+		"prefix":       {title: "en:Fires", exp: "Fire"},
+		"prefix_other": {title: "de:Fire", err: "not de"},
+	}
+
+	for archive, path := range archives {
+		z, err := OpenZIM(path)
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		for name, tc := range tests {
+			t.Run(archive+"/"+name, func(t *testing.T) {
+				page, err := z.Fetch(tc.title)
+				if tc.err != "" {
+					if assert.Error(t, err) {
+						assert.Contains(t, err.Error(), tc.err)
+					}
+					return
+				}
+
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Equal(t, tc.exp, page.Title)
+				assert.Equal(t, "https://en.wikipedia.org/wiki/"+tc.exp, page.URL)
+				assert.Equal(t, html, page.HTML)
+
+				p := NewArticleParser(WithLinkMode(LinkInline))
+				a, err := p.ParsePage(page)
+				assert.NoError(t, err)
+				assert.Equal(t, "# "+tc.exp+"\n\n[Hearth](https://en.wikipedia.org/wiki/Hearth) fire\n\n", p.RenderBody(a))
+			})
+		}
+		assert.NoError(t, z.Close())
+	}
+}
+
+func TestOpenZIMInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.zim")
+	assert.NoError(t, os.WriteFile(path, bytes.Repeat([]byte{1}, 100), 0644))
+
+	_, err := OpenZIM(path)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not a ZIM file")
+	}
+}